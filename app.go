@@ -1,24 +1,32 @@
 package main
 
 import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/kujtimiihoxha/vimtea"
+	"github.com/treethought/perigee/layout"
+	"github.com/treethought/perigee/midi"
 	posc "github.com/treethought/perigee/osc"
+	"github.com/treethought/perigee/watch"
 )
 
-type tidalMsg string
-type sclangMsg string
+type tidalMsg outputLine
+type sclangMsg outputLine
 type oscMsg string
+type watchMsg []string
 
-func listenSclang(ch chan string) tea.Cmd {
+func listenSclang(ch chan outputLine) tea.Cmd {
 	return func() tea.Msg {
 		return sclangMsg(<-ch)
 	}
 }
 
-func listenTidal(ch chan string) tea.Cmd {
+func listenTidal(ch chan outputLine) tea.Cmd {
 	return func() tea.Msg {
 		return tidalMsg(<-ch)
 	}
@@ -30,6 +38,12 @@ func listenOsc(ch chan string) tea.Cmd {
 	}
 }
 
+func listenWatch(ch chan []string) tea.Cmd {
+	return func() tea.Msg {
+		return watchMsg(<-ch)
+	}
+}
+
 func sclangStartCmd(sclang *SCLangRepl) tea.Cmd {
 	return func() tea.Msg {
 		return sclang.Start()
@@ -50,6 +64,12 @@ func oscStartCmd(osc *posc.Server) tea.Cmd {
 	}
 }
 
+// inputCapturer is implemented by components that take text input,
+// which should receive keys before the app's global bindings
+type inputCapturer interface {
+	CapturingInput() bool
+}
+
 type keyMap struct {
 	Quit                key.Binding
 	FocusEditor         key.Binding
@@ -61,6 +81,18 @@ type keyMap struct {
 	FocusFileBrowser    key.Binding
 	ToggleAudioBrowser  key.Binding
 	ToggleVisuals       key.Binding
+	ToggleControls      key.Binding
+	InsertSnippet       key.Binding
+	OpenBlocks          key.Binding
+	ToggleSetList       key.Binding
+	CycleQuantize       key.Binding
+	CancelQueued        key.Binding
+	ShowHelp            key.Binding
+	GrowWidth           key.Binding
+	ShrinkWidth         key.Binding
+	GrowHeight          key.Binding
+	ShrinkHeight        key.Binding
+	NextLayout          key.Binding
 }
 
 var defaultKeyMap = keyMap{
@@ -88,10 +120,10 @@ var defaultKeyMap = keyMap{
 		key.WithKeys("ctrl+o"),
 		key.WithHelp("ctrl+o", "toggle osc console"),
 	),
-	// FocusQuickSelect: key.NewBinding(
-	// 	key.WithKeys("ctrl+o"),
-	// 	key.WithHelp("ctrl+o", "open quick select"),
-	// ),
+	FocusQuickSelect: key.NewBinding(
+		key.WithKeys("ctrl+k"),
+		key.WithHelp("ctrl+k", "open command palette"),
+	),
 	FocusFileBrowser: key.NewBinding(
 		key.WithKeys("ctrl+f"),
 		key.WithHelp("ctrl+f", "open file browser"),
@@ -104,6 +136,54 @@ var defaultKeyMap = keyMap{
 		key.WithKeys("ctrl+p"),
 		key.WithHelp("ctrl+p", "toggle visuals"),
 	),
+	ToggleControls: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "toggle controls"),
+	),
+	InsertSnippet: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "insert snippet"),
+	),
+	OpenBlocks: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+		key.WithHelp("ctrl+n", "eval or jump to named block"),
+	),
+	ToggleSetList: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "toggle set list"),
+	),
+	CycleQuantize: key.NewBinding(
+		key.WithKeys("alt+q"),
+		key.WithHelp("alt+q", "quantize evaluations: off, cycle, bar"),
+	),
+	CancelQueued: key.NewBinding(
+		key.WithKeys("alt+c"),
+		key.WithHelp("alt+c", "cancel queued evaluations"),
+	),
+	ShowHelp: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "show key bindings"),
+	),
+	GrowWidth: key.NewBinding(
+		key.WithKeys("alt+l"),
+		key.WithHelp("alt+l", "widen focused pane"),
+	),
+	ShrinkWidth: key.NewBinding(
+		key.WithKeys("alt+h"),
+		key.WithHelp("alt+h", "narrow focused pane"),
+	),
+	GrowHeight: key.NewBinding(
+		key.WithKeys("alt+j"),
+		key.WithHelp("alt+j", "heighten focused pane"),
+	),
+	ShrinkHeight: key.NewBinding(
+		key.WithKeys("alt+k"),
+		key.WithHelp("alt+k", "shorten focused pane"),
+	),
+	NextLayout: key.NewBinding(
+		key.WithKeys("alt+n"),
+		key.WithHelp("alt+n", "next layout preset"),
+	),
 }
 
 type App struct {
@@ -116,13 +196,34 @@ type App struct {
 	sclang *SCLangRepl
 	// scConsole     *Console
 	qs            *QuickSelect
+	help          *HelpView
 	fileBrowser   *FileBrowser
 	sampleBrowser *SampleBrowser
+	sampleDB      *SampleDB
 	visuals       *VisualsView
+	watcher       *watch.Watcher
+	browseDir     string
+	reloadPrompt  bool
 	active        tea.Model
-	activeConsole *Console
 	h, w          int
 	consoles      map[string]*Console
+	consoleDeck   *ConsoleDeck
+	dirt          *dirtMonitor
+	server        *serverMonitor
+	controls      *ControlsPanel
+	clock         *cycleClock
+	queue         *evalQueue
+	setList       *SetListPanel
+	midi          *midiMapper
+	commands      *commandRegistry
+	recent        *recentFiles
+
+	layouts    map[string]*layout.Node
+	layoutName string
+	layout     *layout.Node
+	// drag is the pane edge being dragged with the mouse
+	drag      *layout.Divider
+	lastClick click
 }
 
 func NewApp(cfg *Config) *App {
@@ -137,23 +238,182 @@ func NewApp(cfg *Config) *App {
 	})
 
 	consoles := map[string]*Console{
-		"osc":    NewConsole(0, 0),
-		"sclang": NewConsole(0, 0),
-		"tidal":  NewConsole(0, 0),
+		"osc":    NewConsole("osc", cfg.ConsoleScrollback),
+		"sclang": NewConsole("sclang", cfg.ConsoleScrollback),
+		"tidal":  NewConsole("tidal", cfg.ConsoleScrollback),
+	}
+
+	clock := newCycleClock(time.Now())
+	quantize, _ := parseQuantize(cfg.Quantize)
+	queue := newEvalQueue(clock, repl.Send, quantize, cfg.BarCycles)
+
+	editor := NewEditor(queue.Send)
+	editor.SetSynths(cfg.Synths)
+
+	watcher, err := watch.NewWatcher(300 * time.Millisecond)
+	if err != nil {
+		log.Println("file watching disabled:", err)
+	}
+
+	sampleDB, err := LoadSampleDB(filepath.Join(configDir(), "samples.json"))
+	if err != nil {
+		log.Println("Error loading sample db, changes to it won't be saved:", err)
 	}
+	sampleBrowser := NewSampleBrowser()
+	sampleBrowser.SetDB(sampleDB)
+	sampleBrowser.SetIndexPath(filepath.Join(cacheDir(), "sample-index.json"))
 
-	return &App{
+	ctrlPort := cfg.CtrlPort
+	if ctrlPort == 0 {
+		ctrlPort = defaultCtrlPort
+	}
+
+	a := &App{
 		cfg:           cfg,
 		osc:           osc,
 		repl:          repl,
 		sclang:        sclang,
 		consoles:      consoles,
-		editor:        NewEditor(repl.Send),
+		consoleDeck:   NewConsoleDeck(consoles, cfg.TileConsoles),
+		dirt:          newDirtMonitor(),
+		server:        newServerMonitor(cfg.ServerPort, cfg.ServerCPUWarning),
+		controls:      NewControlsPanel(posc.NewClient("127.0.0.1", ctrlPort), cfg.Controls),
+		clock:         clock,
+		queue:         queue,
+		setList:       NewSetListPanel(clock),
+		midi:          newMIDIMapper(cfg.MIDI, filepath.Join(configDir(), "midi.json")),
+		editor:        editor,
 		qs:            NewQuickSelect(),
+		help:          NewHelpView(),
 		fileBrowser:   NewFileBrowser(),
-		sampleBrowser: NewSampleBrowser(),
+		sampleBrowser: sampleBrowser,
+		sampleDB:      sampleDB,
 		visuals:       visuals,
+		watcher:       watcher,
+		commands:      &commandRegistry{},
+		recent:        loadRecentFiles(filepath.Join(configDir(), "recent.json")),
+		layouts:       layoutPresets(cfg.Layouts),
+	}
+	a.layoutName = cfg.Layout
+	if a.layoutName == "" {
+		a.layoutName = "default"
 	}
+	a.layout = a.layouts[a.layoutName].Clone()
+	a.registerCommands()
+	return a
+}
+
+// watchDirs starts watching directories for changes
+func (a *App) watchDirs(dirs ...string) tea.Cmd {
+	if a.watcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		for _, dir := range dirs {
+			if err := a.watcher.Add(dir); err != nil {
+				log.Printf("failed to watch %s: %v", dir, err)
+			}
+		}
+		return nil
+	}
+}
+
+// watchBrowseDir moves the watch from the previously browsed directory to
+// dir. It runs on the update loop so quick directory changes can't reorder
+// the adds and removes.
+func (a *App) watchBrowseDir(dir string) tea.Cmd {
+	if a.watcher == nil {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	if prev := a.browseDir; prev != "" && prev != dir && !a.keepWatching(prev) {
+		a.watcher.Remove(prev)
+	}
+	a.browseDir = dir
+	if err := a.watcher.Add(dir); err != nil {
+		log.Printf("failed to watch %s: %v", dir, err)
+	}
+	return nil
+}
+
+// keepWatching reports whether a directory is watched for the open file
+// or the sample banks
+func (a *App) keepWatching(dir string) bool {
+	if dir == filepath.Dir(a.editor.FilePath()) {
+		return true
+	}
+	for _, root := range a.sampleBrowser.roots {
+		root = filepath.Clean(root)
+		if dir == root || filepath.Dir(dir) == root {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) watchSamples() tea.Cmd {
+	if a.watcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return a.watchDirs(a.sampleBrowser.WatchDirs()...)()
+	}
+}
+
+func (a *App) watchStartCmd() tea.Cmd {
+	if a.watcher == nil {
+		return nil
+	}
+	return tea.Batch(
+		func() tea.Msg {
+			a.watcher.Start()
+			return nil
+		},
+		listenWatch(a.watcher.Out()),
+	)
+}
+
+// handleWatch updates the browsers and editor for changed paths
+func (a *App) handleWatch(paths []string) tea.Cmd {
+	cmds := []tea.Cmd{listenWatch(a.watcher.Out())}
+
+	var banks []string
+	seen := make(map[string]struct{})
+	refreshFiles := false
+	fileChanged := false
+
+	for _, p := range paths {
+		if bank, ok := a.sampleBrowser.BankOf(p); ok {
+			if _, ok := seen[bank]; !ok {
+				seen[bank] = struct{}{}
+				banks = append(banks, bank)
+			}
+		}
+		if filepath.Dir(p) == filepath.Clean(a.fileBrowser.Dir()) {
+			refreshFiles = true
+		}
+		if p == a.editor.FilePath() {
+			fileChanged = true
+		}
+	}
+
+	if len(banks) > 0 {
+		cmds = append(cmds, a.sampleBrowser.reloadBanks(banks))
+	}
+	if refreshFiles {
+		cmds = append(cmds, a.fileBrowser.Refresh())
+	}
+	if fileChanged && a.editor.ChangedOnDisk() {
+		if !a.editor.Modified() {
+			cmds = append(cmds, a.editor.Reload())
+			return tea.Batch(cmds...)
+		}
+		a.reloadPrompt = true
+		cmds = append(cmds, a.editor.e.SetStatusMessage(
+			fmt.Sprintf("%s changed on disk, reload? [y/n]", a.editor.currentFile),
+		))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (a *App) focusEditor() tea.Cmd {
@@ -163,27 +423,172 @@ func (a *App) focusEditor() tea.Cmd {
 		a.editor.e.SetMode(vimtea.ModeNormal)
 	}
 	a.active = a.editor
+	a.SetSize(a.w, a.h)
 	return a.editor.e.SetStatusMessage("")
 }
 
-func (a *App) SetActive(m tea.Model) {
-	a.active = m
+func (a *App) focusFileBrowser() tea.Cmd {
+	a.fileBrowser.SetActive(true)
+	a.active = a.fileBrowser
+	a.SetSize(a.w, a.h)
+	return a.editor.e.SetStatusMessage("file browser focused")
+}
+
+func (a *App) toggleSampleBrowser() tea.Cmd {
+	a.sampleBrowser.SetActive(!a.sampleBrowser.Active())
+	if a.sampleBrowser.Active() {
+		a.active = a.sampleBrowser
+		a.SetSize(a.w, a.h)
+		return a.editor.e.SetStatusMessage("audio browser")
+	}
+	a.active = a.editor
+	a.SetSize(a.w, a.h)
+	return nil
+}
+
+// setControlsActive shows or hides the controls panel, refreshing the
+// controls found in the buffer when shown
+func (a *App) setControlsActive(active bool) {
+	if active {
+		a.controls.SetDiscovered(a.editor.Controls())
+	}
+	a.controls.SetActive(active)
+}
+
+func (a *App) toggleControls() tea.Cmd {
+	a.setControlsActive(!a.controls.Active())
+	if a.controls.Active() {
+		a.active = a.controls
+		a.SetSize(a.w, a.h)
+		return a.editor.e.SetStatusMessage("controls")
+	}
+	a.active = a.editor
+	a.SetSize(a.w, a.h)
+	return nil
+}
+
+// syncControls picks up controls added to or removed from the buffer
+func (a *App) syncControls() {
+	if a.controls.Active() {
+		a.controls.SetDiscovered(a.editor.Controls())
+	}
 }
 
-func (a *App) setActiveConsole(val string) {
-	if c, ok := a.consoles[val]; ok {
-		a.activeConsole = c
-		return
+func (a *App) toggleSetList() tea.Cmd {
+	a.setList.SetActive(!a.setList.Active())
+	if a.setList.Active() {
+		a.setList.SetEntries(a.editor.SetList())
+		a.active = a.setList
+		a.SetSize(a.w, a.h)
+		return a.editor.e.SetStatusMessage("set list")
+	}
+	a.active = a.editor
+	a.SetSize(a.w, a.h)
+	return nil
+}
+
+// syncSetList picks up edits to the set list, which keeps playing while
+// hidden
+func (a *App) syncSetList() {
+	a.setList.SetEntries(a.editor.SetList())
+}
+
+// findSample opens the sample browser's finder searching for a sound name
+func (a *App) findSample(name string) tea.Cmd {
+	a.sampleBrowser.SetActive(true)
+	a.active = a.sampleBrowser
+	a.SetSize(a.w, a.h)
+	return a.sampleBrowser.Find(name)
+}
+
+// indicators renders the app state shown in the editor's info line
+func (a *App) indicators() string {
+	s := a.dirt.Indicator(time.Now()) + "  " + a.server.Indicator()
+	if q := a.queue.Indicator(); q != "" {
+		s = q + "  " + s
 	}
-	a.activeConsole = a.consoles["tidal"]
+	if set := a.setList.Indicator(); set != "" {
+		s = set + "  " + s
+	}
+	return s
+}
+
+func (a *App) toggleVisuals() tea.Cmd {
+	// TODO: determine if we need to listen for osc based on active visual
+	// currently enabling if osc console hasn't been activated to start osc listen
+	var cmd tea.Cmd = nil
+	if !a.consoles["osc"].Active() {
+		cmd = listenOsc(a.osc.Out())
+	}
+	a.visuals.SetActive(!a.visuals.Active())
+	if a.visuals.Active() {
+		// no need to focus visuals
+		a.SetSize(a.w, a.h)
+		return tea.Batch(cmd, a.editor.e.SetStatusMessage("visuals enabled"))
+	}
+	a.active = a.editor
+	a.SetSize(a.w, a.h)
+	return cmd
+}
+
+// showVisual switches to the named visual, enabling visuals if needed
+func (a *App) showVisual(name string) tea.Cmd {
+	cmds := []tea.Cmd{a.visuals.SetActiveModel(name)}
+	if !a.visuals.Active() {
+		cmds = append(cmds, a.toggleVisuals())
+	}
+	return tea.Batch(cmds...)
+}
+
+// openHelp shows the bindings of the focused component and the global ones
+func (a *App) openHelp() tea.Cmd {
+	var sections []helpSection
+	if p, ok := a.active.(helpProvider); ok {
+		sections = p.KeyHelp()
+	}
+	sections = append(sections, scopeHelp("global"))
+	a.active = a.help
+	a.help.Open(sections)
+	return nil
+}
+
+// keyConflictsCmd logs conflicting key bindings and reports them in the status line
+func (a *App) keyConflictsCmd() tea.Cmd {
+	conflicts := findKeyConflicts()
+	if len(conflicts) == 0 {
+		return nil
+	}
+	for _, c := range conflicts {
+		log.Println("key conflict:", c)
+	}
+	msg := "key conflict: " + conflicts[0].String()
+	if len(conflicts) > 1 {
+		msg += fmt.Sprintf(" (+%d more, see log)", len(conflicts)-1)
+	}
+	return a.editor.e.SetStatusMessage(msg)
+}
+
+func (a *App) openPalette() tea.Cmd {
+	a.active = a.qs
+	return a.qs.Open(a.commands.All())
+}
+
+func (a *App) SetActive(m tea.Model) {
+	a.active = m
 }
 
 func (a *App) openFile(path string) tea.Cmd {
 	a.fileBrowser.SetActive(false)
 	a.SetActive(a.editor)
-	return tea.Sequence(
-		a.editor.load(path),
-		a.editor.e.SetStatusMessage(path),
+	a.SetSize(a.w, a.h)
+	a.reloadPrompt = false
+	return tea.Batch(
+		a.recent.Add(path),
+		tea.Sequence(
+			a.editor.load(path),
+			a.editor.e.SetStatusMessage(path),
+		),
+		a.watchDirs(filepath.Dir(path)),
 	)
 }
 
@@ -195,24 +600,23 @@ func (a *App) playAudio(path string) tea.Cmd {
 
 func (a *App) Init() tea.Cmd {
 	a.visuals.SetActiveModel("harmonica")
-	a.setActiveConsole("tidal")
-	a.activeConsole.SetActive(true)
-
 	a.SetActive(a.editor)
-	a.visuals.SetActive(false)
-	a.qs.SetOnSelect(func(item *selectItem) tea.Cmd {
-		if item == nil {
-			return nil
-		}
-		a.setActiveConsole(item.value)
-		a.qs.SetActive(false)
-		return nil
+	layoutCmd := a.applyLayout(a.layoutName)
+	a.qs.SetOnSelect(func(c command, arg string) tea.Cmd {
+		a.active = a.editor
+		return c.run(arg)
 	})
 
 	a.fileBrowser.SetDirectory(expandPath(a.cfg.TidalFilesDir))
 	a.fileBrowser.SetOnSelect(a.openFile)
+	a.fileBrowser.SetOnChangeDir(a.watchBrowseDir)
 
+	a.sampleBrowser.SetExtensions(a.cfg.SampleExtensions)
 	a.sampleBrowser.SetOnSelect(a.playAudio)
+	a.sampleBrowser.SetOnInsert(a.editor.insertAtCursor)
+	a.sampleBrowser.SetOnYank(a.editor.yank)
+	a.editor.SetIndicators(a.indicators)
+	a.setList.SetOnPlay(a.evalSetEntry, a.queue.StartsAt)
 
 	return tea.Batch(
 		a.editor.Init(),
@@ -220,86 +624,68 @@ func (a *App) Init() tea.Cmd {
 		a.fileBrowser.Init(),
 		a.sampleBrowser.Init(),
 		a.visuals.Init(),
-		a.sampleBrowser.SetDirectory(expandPath(a.cfg.SamplesDir)),
+		a.sampleBrowser.SetDirectories(a.cfg.SampleRoots()...),
 		sclangStartCmd(a.sclang),
 		replStartCmd(a.repl),
 		oscStartCmd(a.osc),
 		listenTidal(a.repl.out),
 		listenSclang(a.sclang.out),
 		listenOsc(a.osc.Out()),
-		a.editor.load("perigee.tidal"),
+		listenCycles(a.osc.Ticks()),
+		listenEvalQueue(a.queue.wake),
+		a.server.poll(),
+		a.midi.start(),
+		a.editor.load(defaultFile),
+		a.recent.Add(defaultFile),
+		a.watchStartCmd(),
+		a.watchBrowseDir(expandPath(a.cfg.TidalFilesDir)),
+		a.watchDirs(filepath.Dir(a.editor.FilePath())),
+		a.keyConflictsCmd(),
+		layoutCmd,
 	)
 }
 
 func (a *App) SetSize(width, height int) {
 	a.w = width
 	a.h = height
-	// pop over so get desired height without calcs
-	a.qs.SetSize(a.w/2, a.h/2)
-	a.fileBrowser.SetSize(a.w/2, a.h)
-
-	ch, vw, sw := 0, 0, 0
-
-	// console height
-	if a.activeConsole != nil {
-		ch = a.h / 4
-		if ch < 10 {
-			ch = 10
-		}
-		for _, c := range a.consoles {
-			c.SetSize(a.w, ch)
-		}
-	}
-
-	if a.visuals.Active() {
-		vw = a.w / 3
-		if vw < 10 {
-			vw = 10
-		}
-		a.visuals.SetSize(vw, a.h-ch-3)
-	}
-
-	// side samples width
-	if a.sampleBrowser.Active() {
-		sw = a.w / 3
-		if sw < 16 {
-			sw = 16
+	// overlays are centered over the layout
+	a.qs.SetSize(a.w, a.h)
+	a.help.SetSize(a.w, a.h)
+
+	for pane, r := range a.layout.Layout(a.w, a.h, a.paneVisible) {
+		switch pane {
+		case paneEditor:
+			a.editor.SetSize(r.W, r.H-1)
+		case paneConsole:
+			a.consoleDeck.SetSize(r.W, r.H)
+		case paneVisuals:
+			// leave room for the border
+			a.visuals.SetSize(r.W-2, r.H-2)
+		case paneSamples:
+			a.sampleBrowser.SetSize(r.W-2, r.H-2)
+		case paneFiles:
+			// leave room for the title
+			a.fileBrowser.SetSize(r.W, r.H-1)
+		case paneControls:
+			a.controls.SetSize(r.W, r.H)
+		case paneSetList:
+			a.setList.SetSize(r.W, r.H)
 		}
-		a.sampleBrowser.SetSize(sw, a.h-ch-3)
 	}
-
-	a.editor.SetSize(a.w-sw-vw, a.h-ch-1) // Reserve space for console
-	return
 }
 
-func (a *App) selectConsole(c string) tea.Cmd {
-	var selected *Console
-
-	for n, cc := range a.consoles {
-		if n == c {
-			cc.SetActive(true)
-			selected = cc
-			continue
-		}
-		cc.SetActive(false)
+// selectConsole shows and focuses a console, or hides it when shown
+func (a *App) selectConsole(name string) tea.Cmd {
+	shown := a.consoleDeck.Toggle(name)
+	a.SetSize(a.w, a.h)
+	if shown {
+		a.active = a.consoleDeck
+		return a.editor.e.SetStatusMessage(name)
 	}
-
-	if selected == nil || selected == a.activeConsole {
-		a.activeConsole.SetActive(false)
-		a.activeConsole = nil
-		a.SetSize(a.w, a.h)
+	if !a.consoleDeck.Active() {
 		return a.focusEditor()
 	}
-
-	a.activeConsole = selected
-	a.active = a.activeConsole
-	a.SetSize(a.w, a.h)
-
-	if a.activeConsole != nil {
-		return a.editor.e.SetStatusMessage(c)
-	}
-	return a.focusEditor()
-
+	return nil
 }
 
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -311,18 +697,98 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case tidalMsg:
-		a.consoles["tidal"].AddLine(string(msg))
+		a.consoles["tidal"].AddOutput(outputLine(msg))
 		return a, listenTidal(a.repl.out)
 
+	case samplesLoadedMsg:
+		cmd := a.sampleBrowser.SetSamples(msg.samples)
+		a.editor.SetSampleBanks(msg.Banks())
+		return a, tea.Batch(cmd, a.watchSamples())
+
+	case featuresMsg:
+		return a, a.sampleBrowser.SetFeatures(msg)
+
+	case indexScanMsg:
+		return a, a.sampleBrowser.UpdateIndex(msg)
+
+	case watchMsg:
+		return a, a.handleWatch(msg)
+
+	case serverStatusMsg:
+		cmds = append(cmds, a.server.poll())
+		if msg.err == nil {
+			a.dirt.SetCPU(msg.status.AvgCPU, msg.status.PeakCPU)
+		} else {
+			a.dirt.ClearCPU()
+		}
+		if warning := a.server.Update(msg); warning != "" {
+			cmds = append(cmds, a.editor.e.SetStatusMessage(warning))
+		}
+		return a, tea.Batch(cmds...)
+
+	case midiMsg:
+		return a, a.handleMIDI(midi.Message(msg))
+
+	case midiLearnMsg:
+		return a, a.startMIDILearn(msg.mapping)
+
+	case midiErrMsg:
+		log.Println("failed to open midi device:", msg.err)
+		return a, a.editor.e.SetStatusMessage(fmt.Sprintf("MIDI disabled: %v", msg.err))
+
+	case controlErrMsg:
+		log.Printf("failed to send control %s: %v", msg.name, msg.err)
+		return a, a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send %s: %v", msg.name, msg.err))
+
+	case consoleSavedMsg:
+		if msg.err != nil {
+			log.Println("failed to save console:", msg.err)
+			return a, a.editor.e.SetStatusMessage(fmt.Sprintf("failed to save console: %v", msg.err))
+		}
+		return a, a.editor.e.SetStatusMessage(fmt.Sprintf("saved %d lines to %s", msg.lines, msg.path))
+
+	case vimtea.UndoRedoMsg:
+		_, cmd := a.editor.Update(msg)
+		return a, cmd
+
 	case sclangMsg:
-		a.consoles["sclang"].AddLine(string(msg))
-		return a, listenSclang(a.sclang.out)
+		a.consoles["sclang"].AddOutput(outputLine(msg))
+		cmds = append(cmds, listenSclang(a.sclang.out))
+		if name := a.dirt.Observe(msg.text, time.Now()); name != "" {
+			cmds = append(cmds, a.editor.e.SetStatusMessage(
+				fmt.Sprintf("no sample named '%s', find it from the command palette", name),
+			))
+		}
+		return a, tea.Batch(cmds...)
+
+	case cycleMsg:
+		a.clock.Observe(posc.Tick(msg), time.Now())
+		return a, listenCycles(a.osc.Ticks())
+
+	case evalQueueMsg:
+		cmds = append(cmds, listenEvalQueue(a.queue.wake), a.queue.tick())
+		if n := len(a.queue.pending); n > 0 {
+			next := a.queue.pending[n-1].cycle
+			cmds = append(cmds, a.editor.e.SetStatusMessage(
+				fmt.Sprintf("queued for cycle %d (%s cancels)", int(next), defaultKeyMap.CancelQueued.Help().Key),
+			))
+		}
+		return a, tea.Batch(cmds...)
+
+	case evalReleaseMsg:
+		if err := a.queue.release(); err != nil {
+			cmds = append(cmds, a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err)))
+		}
+		cmds = append(cmds, a.queue.tick())
+		return a, tea.Batch(cmds...)
+
+	case setListTickMsg:
+		_, cmd := a.setList.Update(msg)
+		return a, cmd
 
 	case oscMsg:
 		cmds = append(cmds, listenOsc(a.osc.Out()))
-		if a.consoles["osc"].Active() {
-			a.consoles["osc"].AddLine(string(msg))
-		}
+		a.consoles["osc"].AddLine(string(msg))
 		// Pass OSC message to the visuals model first
 		if a.visuals.Active() && a.visuals.activeModel != nil {
 			_, vcmd := a.visuals.activeModel.Update(msg)
@@ -330,6 +796,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, tea.Batch(cmds...)
 
+	case tea.MouseMsg:
+		return a, a.handleMouse(msg)
+
 	case tea.KeyMsg:
 
 		if a.active == nil {
@@ -337,62 +806,87 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if a.active == a.editor && (a.editor.e.GetMode() != vimtea.ModeNormal) {
+			a.reloadPrompt = false
 			_, cmd := a.editor.Update(msg)
+			a.syncControls()
+			a.syncSetList()
 			return a, cmd
 		}
 
+		if c, ok := a.active.(inputCapturer); ok && c.CapturingInput() && msg.String() != "ctrl+c" {
+			_, cmd := a.active.Update(msg)
+			if (a.active == a.qs && !a.qs.Active()) || (a.active == a.help && !a.help.Active()) {
+				a.active = a.editor
+			}
+			return a, cmd
+		}
+
+		// the prompt waits for the editor, other panes keep their keys
+		if a.reloadPrompt && a.active == a.editor {
+			switch msg.String() {
+			case "y":
+				a.reloadPrompt = false
+				return a, a.editor.Reload()
+			case "n":
+				a.reloadPrompt = false
+				return a, a.editor.e.SetStatusMessage("kept buffer, file on disk differs")
+			}
+			// any other key dismisses the prompt, keeping the buffer
+			a.reloadPrompt = false
+		}
+
 		switch {
 		case key.Matches(msg, defaultKeyMap.Quit):
 			return a, tea.Quit
 		case key.Matches(msg, defaultKeyMap.FocusQuickSelect):
-			a.qs.SetActive(true)
-			a.active = a.qs
-			return a, nil
+			return a, a.openPalette()
+		case key.Matches(msg, defaultKeyMap.ShowHelp):
+			return a, a.openHelp()
+		case key.Matches(msg, defaultKeyMap.GrowWidth):
+			return a, a.resizeFocused(layout.Horizontal, resizeStep)
+		case key.Matches(msg, defaultKeyMap.ShrinkWidth):
+			return a, a.resizeFocused(layout.Horizontal, -resizeStep)
+		case key.Matches(msg, defaultKeyMap.GrowHeight):
+			return a, a.resizeFocused(layout.Vertical, resizeStep)
+		case key.Matches(msg, defaultKeyMap.ShrinkHeight):
+			return a, a.resizeFocused(layout.Vertical, -resizeStep)
+		case key.Matches(msg, defaultKeyMap.NextLayout):
+			return a, a.nextLayout()
 		case key.Matches(msg, defaultKeyMap.FocusFileBrowser):
-			a.fileBrowser.SetActive(true)
-			a.active = a.fileBrowser
-			return a, a.editor.e.SetStatusMessage("file browser focused")
+			return a, a.focusFileBrowser()
 		case key.Matches(msg, defaultKeyMap.FocusConsole):
-			a.active = a.activeConsole
+			if !a.consoleDeck.Active() {
+				return a, nil
+			}
+			a.active = a.consoleDeck
 			return a, a.editor.e.SetStatusMessage("console")
 		case key.Matches(msg, defaultKeyMap.ToggleTidalConsole):
 			return a, a.selectConsole("tidal")
 		case key.Matches(msg, defaultKeyMap.ToggleSclangConsole):
 			return a, a.selectConsole("sclang")
 		case key.Matches(msg, defaultKeyMap.ToggleOscConsole):
-			cmds = []tea.Cmd{a.selectConsole("osc")}
-			if !a.consoles["osc"].Active() {
-				cmds = append(cmds, listenOsc(a.osc.Out()))
-			}
-			return a, tea.Batch(cmds...)
-
+			return a, a.selectConsole("osc")
 		case key.Matches(msg, defaultKeyMap.ToggleAudioBrowser):
-			a.sampleBrowser.SetActive(!a.sampleBrowser.Active())
-			if a.sampleBrowser.Active() {
-				a.active = a.sampleBrowser
-				a.SetSize(a.w, a.h)
-				return a, a.editor.e.SetStatusMessage("audio browser")
-			}
-			a.active = a.editor
-			a.SetSize(a.w, a.h)
-			return a, nil
+			return a, a.toggleSampleBrowser()
 		case key.Matches(msg, defaultKeyMap.ToggleVisuals):
-			// TODO: determine if we need to listen for osc based on active visual
-			// currently enabling if osc console hasn't been activated to start osc listen
-			var cmd tea.Cmd = nil
-			if !a.consoles["osc"].Active() {
-				cmd = listenOsc(a.osc.Out())
-			}
-			a.visuals.SetActive(!a.visuals.Active())
-			if a.visuals.Active() {
-				// no need to focus visuals
-				a.SetSize(a.w, a.h)
-				return a, tea.Batch(cmd, a.editor.e.SetStatusMessage("visuals enabled"))
-			}
-			a.active = a.editor
-			a.SetSize(a.w, a.h)
-			return a, cmd
+			return a, a.toggleVisuals()
+		case key.Matches(msg, defaultKeyMap.ToggleControls):
+			return a, a.toggleControls()
+		case key.Matches(msg, defaultKeyMap.InsertSnippet):
+			return a, a.openSnippets()
+		case key.Matches(msg, defaultKeyMap.OpenBlocks):
+			return a, a.openBlocks()
+		case key.Matches(msg, defaultKeyMap.ToggleSetList):
+			return a, a.toggleSetList()
+		case key.Matches(msg, defaultKeyMap.CycleQuantize):
+			return a, a.cycleQuantize()
+		case key.Matches(msg, defaultKeyMap.CancelQueued):
+			return a, a.cancelQueued()
 		case key.Matches(msg, defaultKeyMap.FocusEditor):
+			if a.midi.learning != nil {
+				a.midi.learning = nil
+				return a, a.editor.e.SetStatusMessage("MIDI learn canceled")
+			}
 			a.SetSize(a.w, a.h)
 			return a, a.focusEditor()
 		}
@@ -405,36 +899,24 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	_, cmd := a.active.Update(msg)
 	cmds = append(cmds, cmd)
+	if a.active == a.editor {
+		a.syncControls()
+		a.syncSetList()
+	}
+	if (a.active == a.controls && !a.controls.Active()) || (a.active == a.setList && !a.setList.Active()) {
+		a.active = a.editor
+		a.SetSize(a.w, a.h)
+	}
 	return a, tea.Batch(cmds...)
 }
 
 func (a *App) View() string {
-	if a.qs.Active() {
-		return a.qs.View()
-	}
-
-	if a.fileBrowser.Active() {
-		return a.fileBrowser.View()
-	}
-
-	vv := ""
-	if a.visuals.Active() {
-		vv = a.visuals.View()
+	view := a.renderLayout()
+	if a.help.Active() {
+		return layout.Center(view, a.help.View(), a.w, a.h)
 	}
-
-	cv := ""
-	if a.activeConsole != nil && a.activeConsole.Active() {
-		cv = a.activeConsole.View()
+	if a.qs.Active() {
+		return layout.Center(view, a.qs.View(), a.w, a.h)
 	}
-
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			a.editor.View(),
-			vv,
-			a.sampleBrowser.View(),
-		),
-		cv,
-	)
+	return view
 }
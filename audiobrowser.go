@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -39,6 +40,20 @@ type audioBrowserKeyMap struct {
 	Quit     key.Binding
 	GoToRoot key.Binding
 	Filter   key.Binding
+
+	InsertRef     key.Binding
+	InsertSnippet key.Binding
+	YankRef       key.Binding
+	YankSnippet   key.Binding
+
+	Favorite             key.Binding
+	AddTag               key.Binding
+	RemoveTag            key.Binding
+	AddToCollection      key.Binding
+	RemoveFromCollection key.Binding
+	Find                 key.Binding
+	Similar              key.Binding
+	SortByFeature        key.Binding
 }
 
 var defaultAudioBrowserKeyMap = audioBrowserKeyMap{
@@ -70,23 +85,89 @@ var defaultAudioBrowserKeyMap = audioBrowserKeyMap{
 		key.WithKeys("/"),
 		key.WithHelp("/", "filter"),
 	),
+	InsertRef: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "insert ref at cursor"),
+	),
+	InsertSnippet: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "insert s/n snippet at cursor"),
+	),
+	YankRef: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "yank ref"),
+	),
+	YankSnippet: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "yank s/n snippet"),
+	),
+	Favorite: key.NewBinding(
+		key.WithKeys("*"),
+		key.WithHelp("*", "toggle favorite"),
+	),
+	AddTag: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "add tag"),
+	),
+	RemoveTag: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "remove tag"),
+	),
+	AddToCollection: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "add to collection"),
+	),
+	RemoveFromCollection: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "remove from collection"),
+	),
+	Find: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "find in all samples"),
+	),
+	Similar: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "find similar samples"),
+	),
+	SortByFeature: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "cycle sort: index, duration, loudness, brightness, attack"),
+	),
 }
 
 type AudioBrowser struct {
 	t        table.Model
 	onSelect func(path string) tea.Cmd
+	onInsert func(text string) tea.Cmd
+	onYank   func(text string) tea.Cmd
 
 	prevFilter string
 	fi         textinput.Model
 	filtering  bool
-	query      string
-	setRows    []table.Row
-	sampleRows map[string][]table.Row
+	files      map[string][]audioFile
+	banks      []string
 	currentSet string
 
+	db         *SampleDB
+	prompt     textinput.Model
+	promptKind promptKind
+	message    string
+
+	finder  *sampleFinder
+	finding bool
+
+	features  map[string]sampleFeatures
+	sortBy    sampleFeature
+	analyzing int
+
+	// indexed of indexTotal folders have been checked by a running index scan
+	indexed, indexTotal int
+
 	// allRows      []table.Row
 	filteredRows []table.Row
-	active       bool
+	// offset is the first row in view
+	offset int
+	active bool
 }
 
 func NewAudioBrowser() *AudioBrowser {
@@ -107,14 +188,15 @@ func NewAudioBrowser() *AudioBrowser {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#333333")).
+		BorderForeground(theme.Surface).
 		BorderBottom(true).
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#333333"))
+		Foreground(theme.SurfaceText).
+		Background(theme.Surface)
+	s.Cell = s.Cell.Foreground(theme.Text)
 	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("#FFFF00")).
-		Background(lipgloss.Color("#333333")).
+		Foreground(theme.Selection).
+		Background(theme.Surface).
 		Bold(true)
 
 	t.SetStyles(s)
@@ -124,12 +206,21 @@ func NewAudioBrowser() *AudioBrowser {
 	fi.Focus()
 	fi.Width = 20
 	fi.Prompt = "🔍 "
-	fi.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
-	fi.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	fi.PromptStyle = lipgloss.NewStyle().Foreground(theme.Info)
+	fi.TextStyle = lipgloss.NewStyle().Foreground(theme.SurfaceText)
+
+	prompt := textinput.New()
+	prompt.Width = 20
+	prompt.PromptStyle = fi.PromptStyle
+	prompt.TextStyle = fi.TextStyle
 
 	m := &AudioBrowser{
-		t:  t,
-		fi: fi,
+		t:        t,
+		fi:       fi,
+		prompt:   prompt,
+		files:    make(map[string][]audioFile),
+		finder:   newSampleFinder(),
+		features: make(map[string]sampleFeatures),
 	}
 
 	return m
@@ -146,6 +237,8 @@ func (m *AudioBrowser) SetSize(width, height int) {
 	m.t.SetWidth(width)
 	m.t.SetHeight(height - 2)
 	m.fi.Width = width - 5 // Adjust text input width
+	m.prompt.Width = width - 20
+	m.finder.SetSize(width, height-1)
 
 	cols := m.t.Columns()
 
@@ -157,6 +250,7 @@ func (m *AudioBrowser) SetSize(width, height int) {
 		cols[2].Width = 0
 	}
 	m.t.SetColumns(cols)
+	m.scrollToCursor()
 }
 
 func truncate(s string, max int) string {
@@ -170,58 +264,423 @@ func (m *AudioBrowser) SetOnSelect(f func(path string) tea.Cmd) {
 	m.onSelect = f
 }
 
-func (m *AudioBrowser) applyFilter() {
-	all := m.setRows
-	if m.currentSet != "" {
-		all = m.sampleRows[m.currentSet]
-	}
-	q := strings.ToLower(m.fi.Value())
-	if q == "" {
-		m.filteredRows = all
-		m.t.SetRows(all)
+// SetOnInsert sets the callback used to insert a sample reference into the editor
+func (m *AudioBrowser) SetOnInsert(f func(text string) tea.Cmd) {
+	m.onInsert = f
+}
+
+// SetOnYank sets the callback used to yank a sample reference into a register
+func (m *AudioBrowser) SetOnYank(f func(text string) tea.Cmd) {
+	m.onYank = f
+}
+
+// selectedRef returns the bank and sample index of the selected row.
+// n is -1 when the bank list is shown rather than a single bank.
+func (m *AudioBrowser) selectedRef() (bank string, n int, ok bool) {
+	row := m.t.SelectedRow()
+	if row == nil {
+		return "", 0, false
+	}
+	if m.currentSet == "" {
+		return row[0], -1, true
+	}
+	i := strings.LastIndex(row[0], ":")
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(row[0][i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return row[0][:i], n, true
+}
+
+// sampleRef formats a Tidal sample reference, e.g. bd:3
+func sampleRef(bank string, n int) string {
+	if n < 0 {
+		return bank
+	}
+	return fmt.Sprintf("%s:%d", bank, n)
+}
+
+// sampleSnippet formats a Tidal sound pattern, e.g. s "bd" # n 3
+func sampleSnippet(bank string, n int) string {
+	if n < 0 {
+		return fmt.Sprintf("s \"%s\"", bank)
+	}
+	return fmt.Sprintf("s \"%s\" # n %d", bank, n)
+}
+
+func (m *AudioBrowser) refAction(f func(text string) tea.Cmd, snippet bool) tea.Cmd {
+	if f == nil {
+		return nil
+	}
+	bank, n, ok := m.selectedRef()
+	if !ok {
+		return nil
+	}
+	if snippet {
+		return tea.Batch(f(sampleSnippet(bank, n)), m.touch(bank, n))
+	}
+	return tea.Batch(f(sampleRef(bank, n)), m.touch(bank, n))
+}
+
+// SetDB sets the database of favorites, tags and collections
+func (m *AudioBrowser) SetDB(db *SampleDB) {
+	m.db = db
+	m.finder.db = db
+}
+
+// touch records a sample as recently used
+func (m *AudioBrowser) touch(bank string, n int) tea.Cmd {
+	if m.db == nil || n < 0 || n >= len(m.files[bank]) {
+		return nil
+	}
+	m.db.Touch(sampleKey(bank, m.files[bank][n].name))
+	m.db.SaveLater()
+	return nil
+}
+
+func (m *AudioBrowser) bankRow(bank string) table.Row {
+	return table.Row{bank, "", fmt.Sprintf("%d", len(m.files[bank]))}
+}
+
+func (m *AudioBrowser) sampleRow(bank string, i int) table.Row {
+	f := m.files[bank][i]
+	row := f.Row(sampleRef(bank, i))
+	if m.db == nil {
+		return row
+	}
+	key := sampleKey(bank, f.name)
+	if m.db.IsFavorite(key) {
+		row[1] = "★ " + row[1]
+	}
+	if tags := m.db.Tags(key); len(tags) > 0 {
+		row[1] = fmt.Sprintf("%s [%s]", row[1], strings.Join(tags, ","))
+	}
+	return row
+}
+
+// featureRow adds the value of the sorted feature to a sample row
+func (m *AudioBrowser) featureRow(row table.Row, path string) table.Row {
+	if m.sortBy == featureIndex {
+		return row
+	}
+	if f, ok := m.features[path]; ok && f.Valid() {
+		row[1] = fmt.Sprintf("%s (%s)", row[1], m.sortBy.Format(f))
+	}
+	return row
+}
+
+// sortByFeature orders sample indices of a bank by the selected feature.
+// Samples that haven't been analyzed sort last.
+func (m *AudioBrowser) sortByFeature(bank string, indices []int) {
+	if m.sortBy == featureIndex {
 		return
 	}
+	files := m.files[bank]
+	sort.SliceStable(indices, func(i, j int) bool {
+		fi, iok := m.features[files[indices[i]].path]
+		fj, jok := m.features[files[indices[j]].path]
+		iok = iok && fi.Valid()
+		jok = jok && fj.Valid()
+		if iok != jok {
+			return iok
+		}
+		return m.sortBy.Value(fi) < m.sortBy.Value(fj)
+	})
+}
+
+// SetFeatures merges analyzed features. pending is how many samples are left to analyze.
+func (m *AudioBrowser) SetFeatures(features map[string]sampleFeatures, pending int) {
+	for path, f := range features {
+		m.features[path] = f
+	}
+	m.analyzing = pending
+	if m.sortBy != featureIndex && m.currentSet != "" {
+		m.applyFilter()
+	}
+}
+
+// ReplaceFeatures sets the features of every sample, dropping stale ones
+func (m *AudioBrowser) ReplaceFeatures(features map[string]sampleFeatures) {
+	m.features = features
+}
+
+// SetIndexing shows the progress of an index scan, total is 0 once it's done
+func (m *AudioBrowser) SetIndexing(done, total int) {
+	m.indexed, m.indexTotal = done, total
+}
 
-	var filtered []table.Row
-	for _, row := range all {
-		if strings.Contains(strings.ToLower(row[0]), q) {
-			filtered = append(filtered, row)
+// MissingFeatures returns the paths of samples that haven't been analyzed
+func (m *AudioBrowser) MissingFeatures() []string {
+	var paths []string
+	for _, bank := range m.banks {
+		for _, f := range m.files[bank] {
+			if _, ok := m.features[f.path]; !ok {
+				paths = append(paths, f.path)
+			}
 		}
 	}
-	m.filteredRows = filtered
-	m.t.SetRows(filtered)
+	return paths
 }
 
-func (m *AudioBrowser) SetFiles(files map[string][]audioFile) tea.Cmd {
-	var sampleSetRows []table.Row
-	sampleRows := make(map[string][]table.Row)
-
-	for set, samples := range files {
-		sampleSetRows = append(sampleSetRows, table.Row{
-			truncate(set, 30), "", fmt.Sprintf("%d", len(files[set])),
-		})
-		sampleRows[set] = make([]table.Row, len(samples))
-		for i, sample := range samples {
-			ref := fmt.Sprintf("%s:%d", set, i)
-			sampleRows[set][i] = sample.Row(ref)
+// maxSimilar is how many similar samples are listed
+const maxSimilar = 50
+
+// findSimilar lists the samples closest to the selected one in the finder
+func (m *AudioBrowser) findSimilar() tea.Cmd {
+	bank, n, ok := m.selectedRef()
+	if !ok || n < 0 || n >= len(m.files[bank]) {
+		m.message = "select a sample first"
+		return nil
+	}
+	f := m.files[bank][n]
+	target, ok := m.features[f.path]
+	if !ok || !target.Valid() {
+		m.message = "sample hasn't been analyzed yet"
+		return nil
+	}
+
+	type candidate struct {
+		entry int
+		dist  float64
+	}
+	var candidates []candidate
+	for i, e := range m.finder.entries {
+		ef, ok := m.features[e.file.path]
+		if !ok || !ef.Valid() || e.file.path == f.path {
+			continue
+		}
+		candidates = append(candidates, candidate{entry: i, dist: featureDistance(target, ef)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+
+	entries := make([]int, 0, maxSimilar)
+	for _, c := range candidates[:min(len(candidates), maxSimilar)] {
+		entries = append(entries, c.entry)
+	}
+	m.finder.ShowResults(fmt.Sprintf("similar to %s", sampleRef(bank, n)), entries)
+	m.finding = true
+	return nil
+}
+
+// bankMatches reports whether any sample of the bank satisfies the query's metadata terms
+func (m *AudioBrowser) bankMatches(bank string, q sampleQuery) bool {
+	for _, f := range m.files[bank] {
+		if q.MatchMeta(m.db, sampleKey(bank, f.name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFilter filters banks, or the samples of the current bank, by the filter query.
+// Plain words match the ref column; tag:, fav: and coll: terms match sample metadata.
+func (m *AudioBrowser) applyFilter() {
+	q := parseSampleQuery(m.fi.Value())
+
+	var rows []table.Row
+	if m.currentSet == "" {
+		for _, bank := range m.banks {
+			if !q.MatchText(bank) {
+				continue
+			}
+			if q.HasMeta() && !m.bankMatches(bank, q) {
+				continue
+			}
+			rows = append(rows, m.bankRow(bank))
+		}
+	} else {
+		var indices []int
+		for i, f := range m.files[m.currentSet] {
+			if !q.MatchText(sampleRef(m.currentSet, i)) {
+				continue
+			}
+			if !q.MatchMeta(m.db, sampleKey(m.currentSet, f.name)) {
+				continue
+			}
+			indices = append(indices, i)
+		}
+		m.sortByFeature(m.currentSet, indices)
+		for _, i := range indices {
+			row := m.sampleRow(m.currentSet, i)
+			rows = append(rows, m.featureRow(row, m.files[m.currentSet][i].path))
 		}
 	}
+	m.filteredRows = rows
+	m.t.SetRows(rows)
+	m.scrollToCursor()
+}
 
-	sort.Slice(sampleSetRows, func(i, j int) bool {
-		return strings.ToLower(sampleSetRows[i][0]) < strings.ToLower(sampleSetRows[j][0])
+func (m *AudioBrowser) SetFiles(files map[string][]audioFile) tea.Cmd {
+	banks := make([]string, 0, len(files))
+	for bank := range files {
+		banks = append(banks, bank)
+	}
+	sort.Slice(banks, func(i, j int) bool {
+		return strings.ToLower(banks[i]) < strings.ToLower(banks[j])
 	})
 
-	m.setRows = sampleSetRows
-	m.sampleRows = sampleRows
-	all := m.setRows
-	if m.currentSet != "" {
-		all = m.sampleRows[m.currentSet]
+	m.files = files
+	m.banks = banks
+	if _, ok := files[m.currentSet]; !ok {
+		m.currentSet = ""
 	}
-	m.filteredRows = all
-	m.t.SetRows(all)
+	m.applyFilter()
+	m.finder.SetFiles(banks, files)
 	return nil
 }
 
+// Find opens the sample finder searching for query
+func (m *AudioBrowser) Find(query string) tea.Cmd {
+	m.finding = true
+	return m.finder.Search(query)
+}
+
+// reveal opens the bank of a sample and selects it
+func (m *AudioBrowser) reveal(bank string, n int) {
+	m.currentSet = bank
+	m.prevFilter = ""
+	m.fi.Reset()
+	m.applyFilter()
+	m.t.SetCursor(n)
+	m.scrollToCursor()
+}
+
+// updateFinder handles keys while the global sample finder is open
+func (m *AudioBrowser) updateFinder(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m.finder.Update(msg)
+	}
+	if key.Matches(keyMsg, defaultSampleFinderKeyMap.Close) {
+		m.finding = false
+		return nil
+	}
+	e, selected := m.finder.Selected()
+	switch {
+	case !selected:
+	case key.Matches(keyMsg, defaultSampleFinderKeyMap.Play):
+		return m.playFound()
+	case key.Matches(keyMsg, defaultSampleFinderKeyMap.Reveal):
+		m.finding = false
+		m.reveal(e.bank, e.n)
+		return nil
+	case key.Matches(keyMsg, defaultSampleFinderKeyMap.Insert):
+		if m.onInsert != nil {
+			return tea.Batch(m.onInsert(sampleRef(e.bank, e.n)), m.touch(e.bank, e.n))
+		}
+		return nil
+	case key.Matches(keyMsg, defaultSampleFinderKeyMap.Yank):
+		if m.onYank != nil {
+			return tea.Batch(m.onYank(sampleRef(e.bank, e.n)), m.touch(e.bank, e.n))
+		}
+		return nil
+	}
+	return m.finder.Update(msg)
+}
+
+// playFound plays the sample under the finder's cursor
+func (m *AudioBrowser) playFound() tea.Cmd {
+	e, ok := m.finder.Selected()
+	if !ok || m.onSelect == nil {
+		return nil
+	}
+	return tea.Batch(m.onSelect(e.file.path), m.touch(e.bank, e.n))
+}
+
+// selectedSample returns the sample under the cursor when a bank is open
+func (m *AudioBrowser) selectedSample() (bank string, f audioFile, ok bool) {
+	bank, n, ok := m.selectedRef()
+	if !ok || n < 0 || n >= len(m.files[bank]) {
+		return "", audioFile{}, false
+	}
+	return bank, m.files[bank][n], true
+}
+
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptAddTag
+	promptRemoveTag
+	promptAddCollection
+	promptRemoveCollection
+)
+
+func (k promptKind) String() string {
+	return [...]string{"", "add tag: ", "remove tag: ", "add to collection: ", "remove from collection: "}[k]
+}
+
+// startPrompt asks for a tag or collection name for the selected sample
+func (m *AudioBrowser) startPrompt(kind promptKind) tea.Cmd {
+	if _, _, ok := m.selectedSample(); !ok || m.db == nil {
+		m.message = "select a sample first"
+		return nil
+	}
+	m.promptKind = kind
+	m.prompt.Prompt = kind.String()
+	m.prompt.Reset()
+	return m.prompt.Focus()
+}
+
+// finishPrompt applies the prompt value to the selected sample
+func (m *AudioBrowser) finishPrompt() tea.Cmd {
+	kind := m.promptKind
+	value := strings.TrimSpace(m.prompt.Value())
+	m.promptKind = promptNone
+	m.prompt.Blur()
+
+	bank, f, ok := m.selectedSample()
+	if !ok || value == "" {
+		return nil
+	}
+	key := sampleKey(bank, f.name)
+	switch kind {
+	case promptAddTag:
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			m.db.AddTag(key, tag)
+		}
+		m.message = fmt.Sprintf("tagged %s: %s", f.name, value)
+	case promptRemoveTag:
+		m.db.RemoveTag(key, value)
+		m.message = fmt.Sprintf("untagged %s: %s", f.name, value)
+	case promptAddCollection:
+		m.db.AddToCollection(value, key)
+		m.message = fmt.Sprintf("added %s to %s", f.name, value)
+	case promptRemoveCollection:
+		m.db.RemoveFromCollection(value, key)
+		m.message = fmt.Sprintf("removed %s from %s", f.name, value)
+	}
+	m.applyFilter()
+	m.db.SaveLater()
+	return nil
+}
+
+func (m *AudioBrowser) toggleFavorite() tea.Cmd {
+	bank, f, ok := m.selectedSample()
+	if !ok || m.db == nil {
+		m.message = "select a sample first"
+		return nil
+	}
+	if m.db.ToggleFavorite(sampleKey(bank, f.name)) {
+		m.message = "starred " + f.name
+	} else {
+		m.message = "unstarred " + f.name
+	}
+	m.applyFilter()
+	m.db.SaveLater()
+	return nil
+}
+
+// CapturingInput reports whether keys are going to the filter or a prompt
+func (m *AudioBrowser) CapturingInput() bool {
+	return m.filtering || m.finding || m.promptKind != promptNone
+}
+
 func (m *AudioBrowser) Init() tea.Cmd {
 	return nil
 }
@@ -230,6 +689,24 @@ func (m *AudioBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Handle keyboard input
+		m.message = ""
+		if m.finding {
+			return m, m.updateFinder(msg)
+		}
+		if m.promptKind != promptNone {
+			switch msg.String() {
+			case "esc":
+				m.promptKind = promptNone
+				m.prompt.Blur()
+				return m, nil
+			case "enter":
+				return m, m.finishPrompt()
+			default:
+				p, cmd := m.prompt.Update(msg)
+				m.prompt = p
+				return m, cmd
+			}
+		}
 		if m.filtering {
 			switch msg.String() {
 			case "esc":
@@ -256,6 +733,36 @@ func (m *AudioBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.active = false
 			return m, nil
 
+		case key.Matches(msg, defaultAudioBrowserKeyMap.InsertRef):
+			return m, m.refAction(m.onInsert, false)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.InsertSnippet):
+			return m, m.refAction(m.onInsert, true)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.YankRef):
+			return m, m.refAction(m.onYank, false)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.YankSnippet):
+			return m, m.refAction(m.onYank, true)
+
+		case key.Matches(msg, defaultAudioBrowserKeyMap.Find):
+			m.finding = true
+			return m, m.finder.Open()
+		case key.Matches(msg, defaultAudioBrowserKeyMap.Similar):
+			return m, m.findSimilar()
+		case key.Matches(msg, defaultAudioBrowserKeyMap.SortByFeature):
+			m.sortBy = (m.sortBy + 1) % numSampleFeatures
+			m.message = "sort by " + m.sortBy.String()
+			m.applyFilter()
+			return m, nil
+		case key.Matches(msg, defaultAudioBrowserKeyMap.Favorite):
+			return m, m.toggleFavorite()
+		case key.Matches(msg, defaultAudioBrowserKeyMap.AddTag):
+			return m, m.startPrompt(promptAddTag)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.RemoveTag):
+			return m, m.startPrompt(promptRemoveTag)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.AddToCollection):
+			return m, m.startPrompt(promptAddCollection)
+		case key.Matches(msg, defaultAudioBrowserKeyMap.RemoveFromCollection):
+			return m, m.startPrompt(promptRemoveCollection)
+
 		case key.Matches(msg, defaultAudioBrowserKeyMap.Back):
 			if m.currentSet != "" {
 				m.currentSet = ""
@@ -271,67 +778,173 @@ func (m *AudioBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, defaultAudioBrowserKeyMap.Select):
-			if m.currentSet == "" {
-				sampleSet := m.t.SelectedRow()[0]
-				m.currentSet = sampleSet
-				m.prevFilter = m.fi.Value()
-				m.fi.Reset()
-				m.t.SetRows(m.sampleRows[m.currentSet])
-				return m, nil
-			}
-			row := m.t.SelectedRow()
-			path := row[2]
-
-			if m.onSelect != nil {
-				return m, m.onSelect(path)
-			}
+			return m, m.selectRow()
 		}
 	}
 
 	var cmd tea.Cmd
 	m.t, cmd = m.t.Update(msg)
+	m.scrollToCursor()
 	return m, cmd
 }
 
+// selectRow opens the selected bank or plays the selected sample
+func (m *AudioBrowser) selectRow() tea.Cmd {
+	if m.t.SelectedRow() == nil {
+		return nil
+	}
+	if m.currentSet == "" {
+		sampleSet := m.t.SelectedRow()[0]
+		m.currentSet = sampleSet
+		m.prevFilter = m.fi.Value()
+		m.fi.Reset()
+		m.applyFilter()
+		m.t.GotoTop()
+		m.scrollToCursor()
+		return nil
+	}
+	row := m.t.SelectedRow()
+	path := row[2]
+
+	if m.onSelect != nil {
+		bank, n, _ := m.selectedRef()
+		return tea.Batch(m.onSelect(path), m.touch(bank, n))
+	}
+	return nil
+}
+
+// moveCursor moves the table cursor by delta rows
+func (m *AudioBrowser) moveCursor(delta int) {
+	m.t.SetCursor(m.t.Cursor() + delta)
+	m.scrollToCursor()
+}
+
+// scrollToCursor keeps the cursor in view, scrolling as little as possible
+func (m *AudioBrowser) scrollToCursor() {
+	m.offset = max(0, min(m.offset, len(m.t.Rows())-m.t.Height()))
+	if m.t.Cursor() < m.offset {
+		m.offset = max(m.t.Cursor(), 0)
+	}
+	if m.t.Cursor() >= m.offset+m.t.Height() {
+		m.offset = m.t.Cursor() - m.t.Height() + 1
+	}
+}
+
+// tableView renders the rows from offset. The table scrolls on its own
+// terms, so it is given only the rows in view.
+func (m *AudioBrowser) tableView() string {
+	t := m.t
+	rows := t.Rows()
+	t.SetRows(rows[m.offset:min(m.offset+t.Height(), len(rows))])
+	t.GotoTop()
+	t.SetCursor(m.t.Cursor() - m.offset)
+	return t.View()
+}
+
+// Click selects the clicked row, double clicking opens the bank or plays
+// the sample
+func (m *AudioBrowser) Click(x, y int, double bool) tea.Cmd {
+	if m.finding {
+		// below the title
+		if !m.finder.Click(y-1) || !double {
+			return nil
+		}
+		return m.playFound()
+	}
+
+	// the rows fill the bottom of the view
+	headerHeight := lipgloss.Height(m.View()) - m.t.Height()
+	row := m.offset + y - headerHeight
+	if y < headerHeight || row >= min(m.offset+m.t.Height(), len(m.t.Rows())) {
+		return nil
+	}
+	m.moveCursor(row - m.t.Cursor())
+	if double {
+		return m.selectRow()
+	}
+	return nil
+}
+
+// Scroll moves the cursor by wheel steps
+func (m *AudioBrowser) Scroll(delta int) tea.Cmd {
+	if m.finding {
+		m.finder.move(delta)
+		return nil
+	}
+	m.moveCursor(delta)
+	return nil
+}
+
+func (m *AudioBrowser) title() string {
+	var status []string
+	if m.indexTotal > 0 {
+		status = append(status, fmt.Sprintf("indexing %d/%d", m.indexed, m.indexTotal))
+	}
+	if m.analyzing > 0 {
+		status = append(status, fmt.Sprintf("analyzing, %d left", m.analyzing))
+	}
+	if len(status) == 0 {
+		return "Sample Browser"
+	}
+	return fmt.Sprintf("Sample Browser (%s)", strings.Join(status, ", "))
+}
+
 func (m *AudioBrowser) View() string {
 	if !m.active {
 		return ""
 	}
-	title := lipgloss.NewStyle().
-		Background(lipgloss.Color("#333333")).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Bold(true).
-		Padding(0, 1).
-		Render(fmt.Sprint("Sample Browser"))
+	title := titleBarStyle.Render(m.title())
+
+	if m.finding {
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			title,
+			m.finder.View(),
+		)
+	}
 
 	var fview string
-	if m.filtering {
+	if m.promptKind != promptNone {
+		fview = lipgloss.NewStyle().
+			Background(theme.Surface).
+			Foreground(theme.SurfaceText).
+			Padding(0, 1).
+			Width(m.t.Width()).
+			Render(m.prompt.View())
+	} else if m.message != "" {
+		fview = lipgloss.NewStyle().
+			Background(theme.Surface).
+			Foreground(theme.Info).
+			Padding(0, 1).
+			Width(m.t.Width()).
+			Render(m.message)
+	} else if m.filtering {
 		fview = lipgloss.NewStyle().
-			Background(lipgloss.Color("#333333")).
-			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(theme.Surface).
+			Foreground(theme.SurfaceText).
 			Padding(0, 1).
 			Width(m.t.Width()).
 			Render(m.fi.View())
 	} else if m.fi.Value() != "" {
 		fview = lipgloss.NewStyle().
-			Background(lipgloss.Color("#333333")).
-			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(theme.Surface).
+			Foreground(theme.SurfaceText).
 			Padding(0, 1).
 			Width(m.t.Width()).
 			Render(fmt.Sprintf("Filter: %s", m.fi.Value()))
 	} else {
 		fview = lipgloss.NewStyle().
-			Background(lipgloss.Color("#333333")).
-			Foreground(lipgloss.Color("#999999")).
+			Background(theme.Surface).
+			Foreground(theme.Muted).
 			Padding(0, 1).
 			Width(m.t.Width()).
-			Render("Press '/' to filter")
+			Render("Press '/' to filter, e.g. tag:kick fav: coll:set1")
 	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Center,
 		title,
 		fview,
-		m.t.View(),
+		m.tableView(),
 	)
 }
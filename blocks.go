@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockMarker names the block starting at it, e.g. -- @intro. Scenes chain
+// named blocks, e.g. -- @scene drop intro bass lead, and -- @set lists the
+// set list.
+var blockMarker = regexp.MustCompile(`^\s*--\s*@([\w.-]+)(.*)$`)
+
+// namedBlock is a block tagged with a marker comment. It runs from the
+// marker to the next blank line.
+type namedBlock struct {
+	name string
+	// row is the marker's row
+	row int
+	// code is the block without its marker
+	code string
+}
+
+// scene evaluates named blocks in order
+type scene struct {
+	name   string
+	row    int
+	blocks []string
+}
+
+// parseBlocks finds the named blocks and scenes in a buffer. When a name is
+// used more than once the first is kept.
+func parseBlocks(lines []string) ([]namedBlock, []scene) {
+	var blocks []namedBlock
+	var scenes []scene
+	seen := make(map[string]bool)
+	for row, line := range lines {
+		match := blockMarker.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name, rest := match[1], strings.Fields(match[2])
+		if name == "set" {
+			continue
+		}
+		if name == "scene" {
+			if len(rest) < 2 || seen[rest[0]] {
+				continue
+			}
+			seen[rest[0]] = true
+			scenes = append(scenes, scene{name: rest[0], row: row, blocks: rest[1:]})
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		end := row
+		for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" {
+			end++
+		}
+		blocks = append(blocks, namedBlock{
+			name: name,
+			row:  row,
+			code: strings.Join(lines[row+1:end+1], "\n"),
+		})
+	}
+	return blocks, scenes
+}
+
+// Blocks returns the named blocks and scenes in the buffer
+func (m *Editor) Blocks() ([]namedBlock, []scene) {
+	text := m.e.GetBuffer().Text()
+	if text != m.blocksText {
+		m.blocksText = text
+		m.blocks, m.scenes = parseBlocks(m.e.GetBuffer().Lines())
+	}
+	return m.blocks, m.scenes
+}
+
+// blockCode returns the code of a named block, or of each block of a scene
+func (m *Editor) blockCode(name string) ([]string, error) {
+	blocks, scenes := m.Blocks()
+	byName := make(map[string]namedBlock, len(blocks))
+	for _, b := range blocks {
+		byName[b.name] = b
+	}
+	if b, ok := byName[name]; ok {
+		if strings.TrimSpace(b.code) == "" {
+			return nil, fmt.Errorf("block @%s is empty", name)
+		}
+		return []string{b.code}, nil
+	}
+	for _, s := range scenes {
+		if s.name != name {
+			continue
+		}
+		var code []string
+		for _, n := range s.blocks {
+			b, ok := byName[n]
+			if !ok {
+				return nil, fmt.Errorf("scene @%s: no block named @%s", name, n)
+			}
+			if strings.TrimSpace(b.code) != "" {
+				code = append(code, b.code)
+			}
+		}
+		return code, nil
+	}
+	return nil, fmt.Errorf("no block named @%s in %s", name, m.currentFile)
+}
+
+// evalBlock sends a named block, or each block of a scene, to Tidal
+// through the evaluation queue
+func (a *App) evalBlock(name string) tea.Cmd {
+	return a.evalBlockWith(name, a.queue.Send)
+}
+
+func (a *App) evalBlockWith(name string, send sendFunc) tea.Cmd {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	code, err := a.editor.blockCode(name)
+	if err != nil {
+		return a.editor.e.SetStatusMessage(err.Error())
+	}
+	cmds := []tea.Cmd{a.editor.e.SetStatusMessage("sent @" + name)}
+	for _, c := range code {
+		if err := send(c); err != nil {
+			return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err))
+		}
+		cmds = append(cmds, sentMsgCmd(c))
+	}
+	return tea.Batch(cmds...)
+}
+
+// jumpToBlock moves the cursor to a block's marker
+func (a *App) jumpToBlock(name string, row int) tea.Cmd {
+	a.editor.e.SetCursor(row, 0)
+	return a.editor.e.SetStatusMessage("@" + name)
+}
+
+// blockCommands lists evaluating and jumping to each named block and scene
+func (a *App) blockCommands() []command {
+	blocks, scenes := a.editor.Blocks()
+	var cmds []command
+	for _, b := range blocks {
+		cmds = append(cmds,
+			command{
+				name: "Eval block: @" + b.name,
+				run:  func(string) tea.Cmd { return a.evalBlock(b.name) },
+			},
+			command{
+				name: fmt.Sprintf("Jump to block: @%s (line %d)", b.name, b.row+1),
+				run:  func(string) tea.Cmd { return a.jumpToBlock(b.name, b.row) },
+			},
+		)
+	}
+	for _, s := range scenes {
+		cmds = append(cmds,
+			command{
+				name: fmt.Sprintf("Eval scene: @%s (%s)", s.name, strings.Join(s.blocks, ", ")),
+				run:  func(string) tea.Cmd { return a.evalBlock(s.name) },
+			},
+			command{
+				name: fmt.Sprintf("Jump to scene: @%s (line %d)", s.name, s.row+1),
+				run:  func(string) tea.Cmd { return a.jumpToBlock(s.name, s.row) },
+			},
+		)
+	}
+	return cmds
+}
+
+// openBlocks opens the palette listing only named blocks and scenes
+func (a *App) openBlocks() tea.Cmd {
+	cmds := a.blockCommands()
+	if len(cmds) == 0 {
+		return a.editor.e.SetStatusMessage("no named blocks, tag one with -- @name")
+	}
+	a.active = a.qs
+	return a.qs.Pick("Eval or jump to block", cmds)
+}
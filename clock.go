@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	posc "github.com/treethought/perigee/osc"
+)
+
+// defaultCPS is Tidal's default tempo, used until events report one
+const defaultCPS = 0.5625
+
+// cycleMsg is the cycle position of an event Tidal played
+type cycleMsg posc.Tick
+
+func listenCycles(ch chan posc.Tick) tea.Cmd {
+	return func() tea.Msg {
+		return cycleMsg(<-ch)
+	}
+}
+
+// cycleClock estimates Tidal's cycle position from the events it plays,
+// running on its last known tempo between them. Before any event arrives
+// it's a local clock starting at cycle 0.
+type cycleClock struct {
+	cycle float64
+	cps   float64
+	at    time.Time
+	// synced is set once an event has been received
+	synced bool
+}
+
+func newCycleClock(now time.Time) *cycleClock {
+	return &cycleClock{cps: defaultCPS, at: now}
+}
+
+// Observe syncs the clock to an event's cycle and tempo
+func (c *cycleClock) Observe(t posc.Tick, now time.Time) {
+	c.cycle = t.Cycle
+	c.cps = t.CPS
+	c.at = now
+	c.synced = true
+}
+
+// Cycle returns the estimated cycle position
+func (c *cycleClock) Cycle(now time.Time) float64 {
+	return c.cycle + now.Sub(c.at).Seconds()*c.cps
+}
+
+func (c *cycleClock) CPS() float64 {
+	return c.cps
+}
+
+// Until returns the time left before the clock reaches a cycle
+func (c *cycleClock) Until(cycle float64, now time.Time) time.Duration {
+	left := (cycle - c.Cycle(now)) / c.cps
+	return time.Duration(max(left, 0) * float64(time.Second))
+}
+
+// String shows the current cycle and tempo, marking a local clock with ~
+func (c *cycleClock) String() string {
+	now := time.Now()
+	s := fmt.Sprintf("cycle %d  %.2f cps", int(math.Floor(c.Cycle(now))), c.cps)
+	if !c.synced {
+		s = "~" + s
+	}
+	return s
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// command is an app action that can be run from the command palette
+type command struct {
+	name string
+	// binding is the shortcut running the same action, if any
+	binding *key.Binding
+	// arg prompts for an argument before running, e.g. "cps"
+	arg string
+	run func(arg string) tea.Cmd
+}
+
+// Keys returns the help text of the command's shortcut
+func (c command) Keys() string {
+	if c.binding == nil || !c.binding.Enabled() {
+		return ""
+	}
+	return c.binding.Help().Key
+}
+
+// commandRegistry holds every command listed in the palette. Sources add
+// commands that depend on app state, like recent files, when it's opened.
+type commandRegistry struct {
+	commands []command
+	sources  []func() []command
+}
+
+func (r *commandRegistry) Register(cmds ...command) {
+	r.commands = append(r.commands, cmds...)
+}
+
+func (r *commandRegistry) RegisterSource(source func() []command) {
+	r.sources = append(r.sources, source)
+}
+
+// All returns the registered commands followed by those of each source
+func (r *commandRegistry) All() []command {
+	all := append([]command(nil), r.commands...)
+	for _, source := range r.sources {
+		all = append(all, source()...)
+	}
+	return all
+}
+
+// registerCommands adds every app action to the command palette
+func (a *App) registerCommands() {
+	a.commands.Register(
+		command{
+			name:    "Toggle tidal console",
+			binding: &defaultKeyMap.ToggleTidalConsole,
+			run:     func(string) tea.Cmd { return a.selectConsole("tidal") },
+		},
+		command{
+			name:    "Toggle sclang console",
+			binding: &defaultKeyMap.ToggleSclangConsole,
+			run:     func(string) tea.Cmd { return a.selectConsole("sclang") },
+		},
+		command{
+			name:    "Toggle osc console",
+			binding: &defaultKeyMap.ToggleOscConsole,
+			run:     func(string) tea.Cmd { return a.selectConsole("osc") },
+		},
+		command{
+			name:    "Toggle sample browser",
+			binding: &defaultKeyMap.ToggleAudioBrowser,
+			run:     func(string) tea.Cmd { return a.toggleSampleBrowser() },
+		},
+		command{
+			name:    "Toggle visuals",
+			binding: &defaultKeyMap.ToggleVisuals,
+			run:     func(string) tea.Cmd { return a.toggleVisuals() },
+		},
+		command{
+			name:    "Toggle controls",
+			binding: &defaultKeyMap.ToggleControls,
+			run:     func(string) tea.Cmd { return a.toggleControls() },
+		},
+		command{
+			name:    "Toggle set list",
+			binding: &defaultKeyMap.ToggleSetList,
+			run:     func(string) tea.Cmd { return a.toggleSetList() },
+		},
+		command{
+			name: "Set list: next entry",
+			run: func(string) tea.Cmd {
+				a.syncSetList()
+				return a.setList.Next()
+			},
+		},
+		command{
+			name: "Set list: previous entry",
+			run: func(string) tea.Cmd {
+				a.syncSetList()
+				return a.setList.Prev()
+			},
+		},
+		command{
+			name: "Set list: toggle auto-advance",
+			run: func(string) tea.Cmd {
+				a.syncSetList()
+				return a.setList.ToggleAuto()
+			},
+		},
+		command{
+			name: "Set list: stop",
+			run: func(string) tea.Cmd {
+				a.setList.Stop()
+				return nil
+			},
+		},
+		command{
+			name:    "Open file browser",
+			binding: &defaultKeyMap.FocusFileBrowser,
+			run:     func(string) tea.Cmd { return a.focusFileBrowser() },
+		},
+		command{
+			name:    "Show key bindings",
+			binding: &defaultKeyMap.ShowHelp,
+			run:     func(string) tea.Cmd { return a.openHelp() },
+		},
+		command{
+			name:    "Hush",
+			binding: &defaultEditorKeyMap.Hush,
+			run: func(string) tea.Cmd {
+				return a.queueTidal("hush")
+			},
+		},
+		command{
+			name: "Set cps",
+			arg:  "cps",
+			run: func(arg string) tea.Cmd {
+				if arg == "" {
+					return nil
+				}
+				return a.queueTidal(fmt.Sprintf("setcps (%s)", arg))
+			},
+		},
+		command{
+			name: "Run snippet",
+			arg:  "tidal",
+			run: func(arg string) tea.Cmd {
+				if arg == "" {
+					return nil
+				}
+				return a.queueTidal(arg)
+			},
+		},
+		command{
+			name:    "Insert snippet",
+			binding: &defaultKeyMap.InsertSnippet,
+			run:     func(string) tea.Cmd { return a.openSnippets() },
+		},
+		command{
+			name:    "Named blocks",
+			binding: &defaultKeyMap.OpenBlocks,
+			run:     func(string) tea.Cmd { return a.openBlocks() },
+		},
+		command{
+			name: "Eval named block",
+			arg:  "block or scene",
+			run: func(arg string) tea.Cmd {
+				if arg == "" {
+					return nil
+				}
+				return a.evalBlock(arg)
+			},
+		},
+		command{
+			name: "Save block as snippet",
+			arg:  "snippet name",
+			run:  func(arg string) tea.Cmd { return a.saveBlockSnippet(arg) },
+		},
+		command{
+			name: "Restart tidal REPL",
+			run: func(string) tea.Cmd {
+				return tea.Batch(
+					a.editor.e.SetStatusMessage("restarting tidal"),
+					restartCmd("tidal", a.repl.Restart),
+				)
+			},
+		},
+		command{
+			name: "Restart sclang",
+			run: func(string) tea.Cmd {
+				return tea.Batch(
+					a.editor.e.SetStatusMessage("restarting sclang"),
+					restartCmd("sclang", a.sclang.Restart),
+				)
+			},
+		},
+		command{
+			name:    "Cycle quantize: off, cycle, bar",
+			binding: &defaultKeyMap.CycleQuantize,
+			run:     func(string) tea.Cmd { return a.cycleQuantize() },
+		},
+		command{
+			name:    "Cancel queued evaluations",
+			binding: &defaultKeyMap.CancelQueued,
+			run:     func(string) tea.Cmd { return a.cancelQueued() },
+		},
+		command{
+			name: "Reset SuperDirt monitor",
+			run: func(string) tea.Cmd {
+				a.dirt.Reset()
+				return nil
+			},
+		},
+		command{
+			name: "MIDI learn",
+			arg:  "action (ctrl NAME, mute N, solo N, hush, eval CODE, block NAME)",
+			run: func(arg string) tea.Cmd {
+				if arg == "" {
+					return nil
+				}
+				mapping, err := parseMIDIAction(arg)
+				if err != nil {
+					return a.editor.e.SetStatusMessage(err.Error())
+				}
+				return a.startMIDILearn(mapping)
+			},
+		},
+		command{
+			name:    "Next layout",
+			binding: &defaultKeyMap.NextLayout,
+			run:     func(string) tea.Cmd { return a.nextLayout() },
+		},
+		command{
+			name:    "Quit",
+			binding: &defaultKeyMap.Quit,
+			run:     func(string) tea.Cmd { return tea.Quit },
+		},
+	)
+
+	a.commands.RegisterSource(func() []command {
+		var cmds []command
+		for _, name := range a.visuals.Names() {
+			cmds = append(cmds, command{
+				name: "Show visual: " + name,
+				run:  func(string) tea.Cmd { return a.showVisual(name) },
+			})
+		}
+		return cmds
+	})
+
+	a.commands.RegisterSource(func() []command {
+		var cmds []command
+		for _, name := range a.layoutNames() {
+			cmds = append(cmds, command{
+				name: "Layout: " + name,
+				run:  func(string) tea.Cmd { return a.switchLayout(name) },
+			})
+		}
+		return cmds
+	})
+
+	a.commands.RegisterSource(a.blockCommands)
+
+	a.commands.RegisterSource(func() []command {
+		var cmds []command
+		for _, name := range a.dirt.Missing() {
+			cmds = append(cmds, command{
+				name: fmt.Sprintf("Find missing sample: %s (%d×)", name, a.dirt.MissingCount(name)),
+				run:  func(string) tea.Cmd { return a.findSample(name) },
+			})
+		}
+		return cmds
+	})
+
+	a.commands.RegisterSource(func() []command {
+		var cmds []command
+		for _, path := range a.recent.Files() {
+			if path == a.editor.FilePath() {
+				continue
+			}
+			cmds = append(cmds, command{
+				name: "Open recent: " + path,
+				run:  func(string) tea.Cmd { return a.openFile(path) },
+			})
+		}
+		return cmds
+	})
+}
+
+// sendTidal sends code to the tidal REPL, reporting failures in the status line
+func (a *App) sendTidal(code string) tea.Cmd {
+	if err := a.repl.Send(code); err != nil {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err))
+	}
+	return nil
+}
+
+// restartCmd restarts a REPL in the background, logging failures
+func restartCmd(name string, restart func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := restart(); err != nil {
+			log.Printf("failed to restart %s: %v", name, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCompletions limits how many candidates are offered at once
+const maxCompletions = 8
+
+type completionItem struct {
+	bank  string
+	count int
+}
+
+func (i completionItem) Label() string {
+	if i.count <= 1 {
+		return fmt.Sprintf("%s :0", i.bank)
+	}
+	return fmt.Sprintf("%s :0-%d", i.bank, i.count-1)
+}
+
+// sampleCompletion holds the candidates for the sample word under the cursor
+type sampleCompletion struct {
+	items    []completionItem
+	selected int
+	row      int
+	start    int
+	word     string
+}
+
+func (c *sampleCompletion) Open() bool {
+	return c != nil && len(c.items) > 0
+}
+
+func (c *sampleCompletion) Move(delta int) {
+	if !c.Open() {
+		return
+	}
+	c.selected = (c.selected + delta + len(c.items)) % len(c.items)
+}
+
+func (c *sampleCompletion) Selected() completionItem {
+	return c.items[c.selected]
+}
+
+// completeSample returns the banks matching the partial word.
+// A word that already names a bank with an index only shows its range.
+func completeSample(banks map[string]int, word string) []completionItem {
+	if word == "" {
+		return nil
+	}
+	if i := strings.IndexByte(word, ':'); i >= 0 {
+		if n, ok := banks[word[:i]]; ok {
+			return []completionItem{{bank: word[:i], count: n}}
+		}
+		return nil
+	}
+
+	var items []completionItem
+	for bank, n := range banks {
+		if strings.HasPrefix(bank, word) {
+			items = append(items, completionItem{bank: bank, count: n})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if len(items[i].bank) != len(items[j].bank) {
+			return len(items[i].bank) < len(items[j].bank)
+		}
+		return items[i].bank < items[j].bank
+	})
+	if len(items) > maxCompletions {
+		items = items[:maxCompletions]
+	}
+	return items
+}
+
+func (c *sampleCompletion) View(width int) string {
+	parts := make([]string, len(c.items))
+	for i, item := range c.items {
+		if i == c.selected {
+			parts[i] = completionSelectedStyle.Render(item.Label())
+			continue
+		}
+		parts[i] = completionStyle.Render(item.Label())
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(parts, "  "))
+}
+
+// sampleDiagnostic reports a sample word that doesn't exist in the samples dir
+type sampleDiagnostic struct {
+	word sampleWord
+}
+
+func (d sampleDiagnostic) String() string {
+	return fmt.Sprintf("unknown sample '%s' (line %d)", d.word.name, d.word.row+1)
+}
+
+// superDirtSynths are sound names provided by SuperDirt synthdefs rather than sample folders
+var superDirtSynths = map[string]struct{}{
+	"soskick": {}, "sossnare": {}, "soshats": {}, "sostoms": {}, "in": {}, "inr": {},
+}
+
+func isSynthName(name string, synths []string) bool {
+	if strings.HasPrefix(name, "super") {
+		return true
+	}
+	if _, ok := superDirtSynths[name]; ok {
+		return true
+	}
+	for _, s := range synths {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleDiagnostics checks every sound pattern in lines against the loaded banks
+func sampleDiagnostics(lines []string, banks map[string]int, synths []string) []sampleDiagnostic {
+	if len(banks) == 0 {
+		return nil
+	}
+	var diags []sampleDiagnostic
+	for _, w := range soundPatternWords(lines) {
+		if _, ok := banks[w.name]; ok {
+			continue
+		}
+		if isSynthName(w.name, synths) {
+			continue
+		}
+		diags = append(diags, sampleDiagnostic{word: w})
+	}
+	return diags
+}
+
+func diagnosticsView(diags []sampleDiagnostic, row, width int) string {
+	if len(diags) == 0 {
+		return ""
+	}
+	// prefer the diagnostic on the cursor line
+	d := diags[0]
+	for _, dd := range diags {
+		if dd.word.row == row {
+			d = dd
+			break
+		}
+	}
+	msg := "⚠ " + d.String()
+	if len(diags) > 1 {
+		msg += fmt.Sprintf(" (+%d more)", len(diags)-1)
+	}
+	return diagnosticStyle.MaxWidth(width).Render(msg)
+}
@@ -1,7 +1,107 @@
 package main
 
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/treethought/perigee/layout"
+)
+
 type Config struct {
 	Bootfile      string `json:"bootfile"`
 	TidalFilesDir string `json:"tidal_files_dir"`
 	SamplesDir    string `json:"samples_dir"`
+	// SampleDirs are extra sample roots loaded after SamplesDir, in order,
+	// like repeated calls to ~dirt.loadSoundFiles
+	SampleDirs []string `json:"sample_dirs"`
+	// SampleExtensions overrides the sound file extensions SuperDirt loads
+	SampleExtensions []string `json:"sample_extensions"`
+	// Synths are sound names that aren't sample folders, e.g. custom synthdefs
+	Synths []string `json:"synths"`
+	// Keys overrides key bindings by scope and action, see keyScopes
+	Keys map[string]map[string][]string `json:"keys"`
+	// Layouts add or replace layout presets by name
+	Layouts map[string]*layout.Node `json:"layouts"`
+	// Layout is the preset used at startup
+	Layout string `json:"layout"`
+	// ConsoleScrollback is how many lines each console keeps, 0 for the default
+	ConsoleScrollback int `json:"console_scrollback"`
+	// TileConsoles shows the consoles side by side instead of in tabs
+	TileConsoles bool `json:"tile_consoles"`
+	// ServerPort is scsynth's UDP port, polled for its status, 57110 when unset
+	ServerPort int `json:"server_port"`
+	// ServerCPUWarning is the average scsynth CPU load in percent that
+	// warns about dropouts, 80 when unset
+	ServerCPUWarning float64 `json:"server_cpu_warning"`
+	// CtrlPort is the port Tidal listens on for /ctrl messages, 6010 when unset
+	CtrlPort int `json:"ctrl_port"`
+	// Controls are shown in the controls panel before those found in the buffer
+	Controls []ControlDef `json:"controls"`
+	// Quantize holds evaluations until the next "cycle" or "bar", off when unset
+	Quantize string `json:"quantize"`
+	// BarCycles is how many cycles make a bar, 4 when unset
+	BarCycles int `json:"bar_cycles"`
+	// MIDI enables MIDI input when set
+	MIDI *MIDIConfig `json:"midi"`
+	// SnippetDirs are shared snippet libraries, searched after the one in
+	// the config dir
+	SnippetDirs []string `json:"snippet_dirs"`
+	// Theme names a bundled or configured theme, dark when unset
+	Theme string `json:"theme"`
+	// Themes add or replace themes by name
+	Themes map[string]Theme `json:"themes"`
+}
+
+// configDir returns the directory holding perigee's config and state files
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = expandPath("~/.config")
+	}
+	return filepath.Join(dir, "perigee")
+}
+
+// cacheDir returns the directory holding perigee's caches, which can be rebuilt
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = expandPath("~/.cache")
+	}
+	return filepath.Join(dir, "perigee")
+}
+
+// loadConfig overlays the config file, if present, onto cfg
+func loadConfig(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cfg)
+}
+
+// SampleRoots returns every configured sample root in load order
+func (c *Config) SampleRoots() []string {
+	var roots []string
+	if c.SamplesDir != "" {
+		roots = append(roots, expandPath(c.SamplesDir))
+	}
+	for _, dir := range c.SampleDirs {
+		roots = append(roots, expandPath(dir))
+	}
+	return roots
+}
+
+// SnippetRoots returns the snippet libraries in search order
+func (c *Config) SnippetRoots() []string {
+	roots := []string{snippetsDir()}
+	for _, dir := range c.SnippetDirs {
+		roots = append(roots, expandPath(dir))
+	}
+	return roots
 }
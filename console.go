@@ -1,52 +1,472 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
-var (
-	consoleStyle = lipgloss.NewStyle().
-		Padding(0, 1).
-		Border(lipgloss.NormalBorder(), true)
+type consoleKeyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	PageUp       key.Binding
+	PageDown     key.Binding
+	HalfPageUp   key.Binding
+	HalfPageDown key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
+	Follow       key.Binding
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
+	Filter       key.Binding
+	CycleLevel   key.Binding
+	ClearFilters key.Binding
+	Save         key.Binding
+	NextConsole  key.Binding
+	PrevConsole  key.Binding
+	ToggleTiled  key.Binding
+}
+
+var defaultConsoleKeyMap = consoleKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup", "b"),
+		key.WithHelp("b/pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown", " "),
+		key.WithHelp("space/pgdn", "page down"),
+	),
+	HalfPageUp: key.NewBinding(
+		key.WithKeys("u", "ctrl+u"),
+		key.WithHelp("u", "½ page up"),
+	),
+	HalfPageDown: key.NewBinding(
+		key.WithKeys("d", "ctrl+d"),
+		key.WithHelp("d", "½ page down"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "oldest line"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "newest line and follow"),
+	),
+	Follow: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "pause/follow output"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match (older)"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match (newer)"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("&"),
+		key.WithHelp("&", "only show lines matching a regex"),
+	),
+	CycleLevel: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "cycle level: all, warnings, errors"),
+	),
+	ClearFilters: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "clear search and filters"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save to file"),
+	),
+	NextConsole: key.NewBinding(
+		key.WithKeys("tab", "]"),
+		key.WithHelp("tab/]", "next console"),
+	),
+	PrevConsole: key.NewBinding(
+		key.WithKeys("shift+tab", "["),
+		key.WithHelp("shift+tab/[", "previous console"),
+	),
+	ToggleTiled: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "tile/tab consoles"),
+	),
+}
+
+// consolePrompt is the input the console is asking for
+type consolePrompt int
+
+const (
+	consolePromptNone consolePrompt = iota
+	consolePromptSearch
+	consolePromptFilter
+	consolePromptSave
 )
 
+// consoleSavedMsg reports the result of saving a console to a file
+type consoleSavedMsg struct {
+	path  string
+	lines int
+	err   error
+}
+
+// Console shows the output of a process. It keeps a bounded scrollback and
+// only renders the lines in view, so high-rate output stays cheap.
 type Console struct {
-	Lines    []string
-	viewport viewport.Model
-	active   bool
+	name   string
+	buf    *scrollback
+	active bool
+	w, h   int
+
+	// follow keeps the newest lines in view. Otherwise top is the first
+	// shown line in view and unseen counts the lines added since pausing.
+	follow bool
+	top    int
+	unseen int
+
+	level  lineLevel
+	filter *regexp.Regexp
+	// shown holds the numbers of the lines passing the filters, nil when
+	// nothing is filtered
+	shown []int
+
+	search *regexp.Regexp
+	// match is the number of the current search match, -1 when there is none
+	match int
+
+	prompt  consolePrompt
+	input   textinput.Model
+	message string
+
+	// unread counts the lines added while the console was hidden, and
+	// unreadLevel is the most severe of them
+	unread      int
+	unreadLevel lineLevel
+
+	// lastKind is the kind of the last line read from the process, so an
+	// error's indented details are classified with it
+	lastKind lineKind
 }
 
-func NewConsole(width, height int) *Console {
+func NewConsole(name string, scrollback int) *Console {
+	input := textinput.New()
+	input.PromptStyle = lipgloss.NewStyle().Foreground(theme.Info)
 	return &Console{
-		Lines:    make([]string, 0),
-		viewport: viewport.New(width, height),
+		name:   name,
+		buf:    newScrollback(scrollback),
+		follow: true,
+		match:  -1,
+		input:  input,
 	}
 }
 
+func (c *Console) KeyHelp() []helpSection {
+	return []helpSection{scopeHelp("console")}
+}
+
 func (c *Console) Active() bool {
 	return c.active
 }
 
 func (c *Console) SetActive(active bool) {
 	c.active = active
+	if active {
+		c.unread = 0
+		c.unreadLevel = levelInfo
+	}
+}
+
+// CapturingInput is true while typing a search, filter or file name
+func (c *Console) CapturingInput() bool {
+	return c.prompt != consolePromptNone
 }
 
 func (c *Console) SetSize(width, height int) {
-	sx, sy := consoleStyle.GetFrameSize()
-	c.viewport.Width = width - sx
-	c.viewport.Height = height - sy
-	c.viewport.SetContent(strings.Join(c.Lines, "\n"))
-	c.viewport.GotoBottom()
+	c.w = width
+	c.h = height
+	c.input.Width = max(width-20, 10)
+	c.clampTop()
 }
 
+// AddLine adds a line of the process's standard output
 func (c *Console) AddLine(line string) {
-	c.Lines = append(c.Lines, line)
-	c.viewport.SetContent(strings.Join(c.Lines, "\n"))
-	c.viewport.GotoBottom()
+	c.AddOutput(outputLine{stream: streamStdout, text: line})
+}
+
+// AddOutput classifies and adds output read from the process or code sent
+// to it. The lines of sent code are grouped in the gutter.
+func (c *Console) AddOutput(o outputLine) {
+	texts := strings.Split(strings.TrimRight(o.text, "\n"), "\n")
+	for i, text := range texts {
+		l := classifyLine(o.stream, text, c.lastKind)
+		if o.stream == streamInput && len(texts) > 1 {
+			switch i {
+			case 0:
+				l.group = groupFirst
+			case len(texts) - 1:
+				l.group = groupLast
+			default:
+				l.group = groupMiddle
+			}
+		} else if o.stream != streamInput {
+			c.lastKind = l.kind
+		}
+		c.addLine(l)
+	}
+}
+
+func (c *Console) addLine(l consoleLine) {
+	if !c.active {
+		c.unread++
+		c.unreadLevel = max(c.unreadLevel, l.level())
+	}
+	dropped := c.buf.Push(l)
+	n := c.buf.First() + c.buf.Len() - 1
+
+	shift := 0
+	if c.shown != nil {
+		for len(c.shown) > 0 && c.shown[0] < c.buf.First() {
+			c.shown = c.shown[1:]
+			shift++
+		}
+		if c.passes(l) {
+			c.shown = append(c.shown, n)
+		}
+	} else if dropped {
+		shift = 1
+	}
+	if c.match >= 0 && c.match < c.buf.First() {
+		c.match = -1
+	}
+
+	if !c.follow {
+		// keep the same lines in view as older ones are dropped
+		c.top = max(c.top-shift, 0)
+		if c.shown == nil || c.passes(l) {
+			c.unseen++
+		}
+	}
+}
+
+func (c *Console) passes(l consoleLine) bool {
+	return l.level() >= c.level && (c.filter == nil || c.filter.MatchString(l.text))
+}
+
+// applyFilters rebuilds the shown lines after the filters change
+func (c *Console) applyFilters() {
+	if c.level == levelInfo && c.filter == nil {
+		c.shown = nil
+	} else {
+		c.shown = []int{}
+		for n := c.buf.First(); n < c.buf.First()+c.buf.Len(); n++ {
+			if c.passes(c.buf.Get(n)) {
+				c.shown = append(c.shown, n)
+			}
+		}
+	}
+	c.follow = true
+	c.unseen = 0
+}
+
+func (c *Console) shownLen() int {
+	if c.shown == nil {
+		return c.buf.Len()
+	}
+	return len(c.shown)
+}
+
+// shownLine returns the number of the i-th shown line
+func (c *Console) shownLine(i int) int {
+	if c.shown == nil {
+		return c.buf.First() + i
+	}
+	return c.shown[i]
+}
+
+// shownIndex returns the position of line n among the shown lines
+func (c *Console) shownIndex(n int) (int, bool) {
+	if c.shown == nil {
+		i := n - c.buf.First()
+		return i, i >= 0 && i < c.buf.Len()
+	}
+	i := sort.SearchInts(c.shown, n)
+	return i, i < len(c.shown) && c.shown[i] == n
+}
+
+func (c *Console) statusVisible() bool {
+	return c.prompt != consolePromptNone || c.message != "" || !c.follow ||
+		c.level != levelInfo || c.filter != nil || c.search != nil
+}
+
+// lineRows is how many lines of output fit inside the border
+func (c *Console) lineRows() int {
+	_, fh := consoleStyle.GetFrameSize()
+	rows := c.h - fh
+	if c.statusVisible() {
+		rows--
+	}
+	return max(rows, 1)
+}
+
+func (c *Console) maxTop() int {
+	return max(c.shownLen()-c.lineRows(), 0)
+}
+
+func (c *Console) clampTop() {
+	c.top = min(max(c.top, 0), c.maxTop())
+}
+
+// scrollBy moves the view by delta lines. Reaching the newest line
+// follows the output again.
+func (c *Console) scrollBy(delta int) {
+	if c.follow {
+		c.top = c.maxTop()
+	}
+	c.follow = false
+	c.top += delta
+	c.clampTop()
+	if delta > 0 && c.top == c.maxTop() {
+		c.followOutput()
+	}
+}
+
+func (c *Console) followOutput() {
+	c.follow = true
+	c.unseen = 0
+}
+
+// Scroll moves the console up or down by wheel steps
+func (c *Console) Scroll(delta int) tea.Cmd {
+	c.scrollBy(delta * wheelLines)
+	return nil
+}
+
+// findMatch moves to the next search match, towards older lines when
+// older is set
+func (c *Console) findMatch(older bool) {
+	if c.search == nil || c.shownLen() == 0 {
+		return
+	}
+	start := c.shownLen()
+	if i, ok := c.shownIndex(c.match); ok && c.match >= 0 {
+		start = i
+	} else if !older {
+		start = -1
+	}
+	step := 1
+	if older {
+		step = -1
+	}
+	for i := start + step; i >= 0 && i < c.shownLen(); i += step {
+		n := c.shownLine(i)
+		if c.search.MatchString(c.buf.Get(n).text) {
+			c.match = n
+			c.follow = false
+			c.top = i - c.lineRows()/2
+			c.clampTop()
+			c.message = ""
+			return
+		}
+	}
+	if older {
+		c.message = "no older matches"
+	} else {
+		c.message = "no newer matches"
+	}
+}
+
+// compilePattern compiles a case insensitive regex, matching the text
+// literally when it isn't a valid one
+func compilePattern(s string) *regexp.Regexp {
+	re, err := regexp.Compile("(?i)" + s)
+	if err != nil {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(s))
+	}
+	return re
+}
+
+func (c *Console) startPrompt(p consolePrompt) tea.Cmd {
+	c.prompt = p
+	c.message = ""
+	c.input.Reset()
+	switch p {
+	case consolePromptSearch:
+		c.input.Prompt = "/"
+	case consolePromptFilter:
+		c.input.Prompt = "filter: "
+		if c.filter != nil {
+			c.input.SetValue(strings.TrimPrefix(c.filter.String(), "(?i)"))
+		}
+	case consolePromptSave:
+		c.input.Prompt = "save to: "
+		c.input.SetValue(fmt.Sprintf("%s-%s.log", c.name, time.Now().Format("20060102-150405")))
+	}
+	c.input.CursorEnd()
+	return c.input.Focus()
+}
+
+func (c *Console) finishPrompt() tea.Cmd {
+	p := c.prompt
+	value := strings.TrimSpace(c.input.Value())
+	c.prompt = consolePromptNone
+	c.input.Blur()
+
+	switch p {
+	case consolePromptSearch:
+		if value == "" {
+			c.search = nil
+			c.match = -1
+			return nil
+		}
+		c.search = compilePattern(value)
+		c.match = -1
+		c.findMatch(true)
+	case consolePromptFilter:
+		c.filter = nil
+		if value != "" {
+			c.filter = compilePattern(value)
+		}
+		c.applyFilters()
+	case consolePromptSave:
+		if value == "" {
+			return nil
+		}
+		return c.save(expandPath(value))
+	}
+	return nil
+}
+
+// save writes every line in the scrollback to path in the background
+func (c *Console) save(path string) tea.Cmd {
+	texts := c.buf.Texts()
+	return func() tea.Msg {
+		data := strings.Join(texts, "\n") + "\n"
+		err := os.WriteFile(path, []byte(data), 0644)
+		return consoleSavedMsg{path: path, lines: len(texts), err: err}
+	}
 }
 
 func (c *Console) Init() tea.Cmd {
@@ -54,14 +474,155 @@ func (c *Console) Init() tea.Cmd {
 }
 
 func (c *Console) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	v, cmd := c.viewport.Update(msg)
-	c.viewport = v
-	return c, cmd
+	if c.prompt != consolePromptNone {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				c.prompt = consolePromptNone
+				c.input.Blur()
+				return c, nil
+			case "enter":
+				return c, c.finishPrompt()
+			}
+		}
+		var cmd tea.Cmd
+		c.input, cmd = c.input.Update(msg)
+		return c, cmd
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+
+	c.message = ""
+	rows := c.lineRows()
+	switch {
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Up):
+		c.scrollBy(-1)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Down):
+		c.scrollBy(1)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.PageUp):
+		c.scrollBy(-rows)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.PageDown):
+		c.scrollBy(rows)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.HalfPageUp):
+		c.scrollBy(-rows / 2)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.HalfPageDown):
+		c.scrollBy(rows / 2)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Top):
+		c.follow = false
+		c.top = 0
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Bottom):
+		c.followOutput()
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Follow):
+		if c.follow {
+			c.top = c.maxTop()
+			c.follow = false
+		} else {
+			c.followOutput()
+		}
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Search):
+		return c, c.startPrompt(consolePromptSearch)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.NextMatch):
+		c.findMatch(true)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.PrevMatch):
+		c.findMatch(false)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Filter):
+		return c, c.startPrompt(consolePromptFilter)
+	case key.Matches(keyMsg, defaultConsoleKeyMap.CycleLevel):
+		c.level = (c.level + 1) % numLineLevels
+		c.applyFilters()
+	case key.Matches(keyMsg, defaultConsoleKeyMap.ClearFilters):
+		c.search = nil
+		c.match = -1
+		c.level = levelInfo
+		c.filter = nil
+		c.applyFilters()
+	case key.Matches(keyMsg, defaultConsoleKeyMap.Save):
+		return c, c.startPrompt(consolePromptSave)
+	}
+	return c, nil
+}
+
+// status summarizes the console's state below the output
+func (c *Console) status() string {
+	if c.message != "" {
+		return c.message
+	}
+	var parts []string
+	if !c.follow {
+		paused := "paused"
+		if c.unseen > 0 {
+			paused = fmt.Sprintf("paused, %d new", c.unseen)
+		}
+		parts = append(parts, paused)
+	}
+	if c.level != levelInfo {
+		parts = append(parts, "level: "+c.level.String())
+	}
+	if c.filter != nil {
+		parts = append(parts, "filter: "+strings.TrimPrefix(c.filter.String(), "(?i)"))
+	}
+	if c.search != nil {
+		parts = append(parts, "search: "+strings.TrimPrefix(c.search.String(), "(?i)"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderLine styles a line by kind behind its gutter marker, highlighting
+// search matches
+func (c *Console) renderLine(n, width int) string {
+	l := c.buf.Get(n)
+	style := l.style()
+	gutter := style.Render(l.gutter()) + " "
+	text := ansi.Truncate(l.text, max(width-2, 1), "…")
+	if c.search == nil {
+		return gutter + style.Render(text)
+	}
+
+	matchStyle := lipgloss.NewStyle().Foreground(theme.Surface).Background(theme.Selection)
+	if n == c.match {
+		matchStyle = matchStyle.Bold(true).Underline(true)
+	}
+	var sb strings.Builder
+	sb.WriteString(gutter)
+	last := 0
+	for _, m := range c.search.FindAllStringIndex(text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		sb.WriteString(style.Render(text[last:m[0]]))
+		sb.WriteString(matchStyle.Render(text[m[0]:m[1]]))
+		last = m[1]
+	}
+	sb.WriteString(style.Render(text[last:]))
+	return sb.String()
 }
 
 func (c *Console) View() string {
-  if !c.active {
-    return ""
-  }
-	return consoleStyle.Render(c.viewport.View())
+	if !c.active {
+		return ""
+	}
+	fw, _ := consoleStyle.GetFrameSize()
+	width := max(c.w-fw, 1)
+	rows := c.lineRows()
+
+	top := c.top
+	if c.follow {
+		top = c.maxTop()
+	}
+	lines := make([]string, 0, rows+1)
+	for i := top; i < min(top+rows, c.shownLen()); i++ {
+		lines = append(lines, c.renderLine(c.shownLine(i), width))
+	}
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+	if c.prompt != consolePromptNone {
+		lines = append(lines, c.input.View())
+	} else if c.statusVisible() {
+		lines = append(lines, completionStyle.Render(ansi.Truncate(c.status(), width, "…")))
+	}
+	return consoleStyle.Width(c.w - 2).Render(strings.Join(lines, "\n"))
 }
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/treethought/perigee/layout"
+)
+
+// consoleOrder is the order of the consoles in the tab bar and the strip
+var consoleOrder = []string{"tidal", "sclang", "osc"}
+
+// ConsoleDeck shows the consoles in the console pane, either as tabs with
+// one console at a time or tiled side by side in a strip. Every console
+// gets a title line, which badges hidden consoles with their unread output.
+type ConsoleDeck struct {
+	consoles []*Console
+	// current is the focused console, the shown tab when not tiled
+	current int
+	tiled   bool
+	// open marks the consoles that have a tile in the strip
+	open   []bool
+	active bool
+	w, h   int
+}
+
+func NewConsoleDeck(consoles map[string]*Console, tiled bool) *ConsoleDeck {
+	d := &ConsoleDeck{tiled: tiled}
+	for _, name := range consoleOrder {
+		if c, ok := consoles[name]; ok {
+			d.consoles = append(d.consoles, c)
+			d.open = append(d.open, true)
+		}
+	}
+	return d
+}
+
+func (d *ConsoleDeck) Active() bool {
+	return d.active
+}
+
+func (d *ConsoleDeck) SetActive(active bool) {
+	d.active = active
+	d.sync()
+}
+
+// Current returns the focused console
+func (d *ConsoleDeck) Current() *Console {
+	return d.consoles[d.current]
+}
+
+func (d *ConsoleDeck) Tiled() bool {
+	return d.tiled
+}
+
+// SetTiled switches between tabs and the strip. The focused console keeps
+// its tile.
+func (d *ConsoleDeck) SetTiled(tiled bool) {
+	d.tiled = tiled
+	d.open[d.current] = true
+	d.sync()
+	d.SetSize(d.w, d.h)
+}
+
+func (d *ConsoleDeck) index(name string) (int, bool) {
+	for i, c := range d.consoles {
+		if c.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// visible reports whether the i-th console is on screen
+func (d *ConsoleDeck) visible(i int) bool {
+	if !d.active {
+		return false
+	}
+	if d.tiled {
+		return d.open[i]
+	}
+	return i == d.current
+}
+
+// sync shows and hides the consoles, which clears the badges of shown ones
+func (d *ConsoleDeck) sync() {
+	for i, c := range d.consoles {
+		c.SetActive(d.visible(i))
+	}
+}
+
+// Toggle shows and focuses the named console, or hides it when it is
+// shown. Closing the last tile of the strip hides the deck. It reports
+// whether the console is shown afterwards.
+func (d *ConsoleDeck) Toggle(name string) bool {
+	i, ok := d.index(name)
+	if !ok {
+		return false
+	}
+	shown := !d.visible(i)
+	switch {
+	case shown:
+		d.active = true
+		d.current = i
+		d.open[i] = true
+	case d.tiled:
+		d.open[i] = false
+		d.active = false
+		for j := range d.open {
+			if d.open[j] {
+				d.active = true
+				if i == d.current {
+					d.current = j
+				}
+			}
+		}
+		if !d.active {
+			// reopen the strip with this console next time
+			d.open[i] = true
+		}
+	default:
+		d.active = false
+	}
+	d.sync()
+	d.SetSize(d.w, d.h)
+	return shown
+}
+
+// focus moves the focus to the next or previous visible console
+func (d *ConsoleDeck) focus(step int) {
+	n := len(d.consoles)
+	for i := 1; i < n; i++ {
+		j := ((d.current+i*step)%n + n) % n
+		if !d.tiled || d.open[j] {
+			d.current = j
+			break
+		}
+	}
+	d.sync()
+}
+
+// tiles returns the area of each visible console below its title line
+func (d *ConsoleDeck) tiles() map[string]layout.Rect {
+	if !d.tiled {
+		return map[string]layout.Rect{
+			d.Current().name: {X: 0, Y: 1, W: d.w, H: d.h - 1},
+		}
+	}
+	strip := &layout.Node{Split: layout.Horizontal}
+	for _, c := range d.consoles {
+		strip.Children = append(strip.Children, &layout.Node{Pane: c.name})
+	}
+	rects := strip.Layout(d.w, d.h, func(name string) bool {
+		i, _ := d.index(name)
+		return d.open[i]
+	})
+	for name, r := range rects {
+		rects[name] = layout.Rect{X: r.X, Y: r.Y + 1, W: r.W, H: r.H - 1}
+	}
+	return rects
+}
+
+func (d *ConsoleDeck) SetSize(width, height int) {
+	d.w = width
+	d.h = height
+	for name, r := range d.tiles() {
+		i, _ := d.index(name)
+		d.consoles[i].SetSize(r.W, r.H)
+	}
+}
+
+func (d *ConsoleDeck) KeyHelp() []helpSection {
+	return d.Current().KeyHelp()
+}
+
+// CapturingInput is true while the focused console takes text input
+func (d *ConsoleDeck) CapturingInput() bool {
+	return d.Current().CapturingInput()
+}
+
+func (d *ConsoleDeck) Init() tea.Cmd {
+	return nil
+}
+
+func (d *ConsoleDeck) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !d.CapturingInput() {
+		switch {
+		case key.Matches(keyMsg, defaultConsoleKeyMap.NextConsole):
+			d.focus(1)
+			return d, nil
+		case key.Matches(keyMsg, defaultConsoleKeyMap.PrevConsole):
+			d.focus(-1)
+			return d, nil
+		case key.Matches(keyMsg, defaultConsoleKeyMap.ToggleTiled):
+			d.SetTiled(!d.tiled)
+			return d, nil
+		}
+	}
+	_, cmd := d.Current().Update(msg)
+	return d, cmd
+}
+
+// Scroll scrolls the focused console
+func (d *ConsoleDeck) Scroll(delta int) tea.Cmd {
+	return d.Current().Scroll(delta)
+}
+
+// Click focuses the console under the pointer, or the clicked tab
+func (d *ConsoleDeck) Click(x, y int, double bool) tea.Cmd {
+	if d.tiled {
+		for name, r := range d.tiles() {
+			if x >= r.X && x < r.X+r.W {
+				d.current, _ = d.index(name)
+			}
+		}
+		return nil
+	}
+	if y != 0 {
+		return nil
+	}
+	pos := 0
+	for i, c := range d.consoles {
+		w := lipgloss.Width(d.label(c, false))
+		if x >= pos && x < pos+w {
+			d.current = i
+			d.sync()
+			d.SetSize(d.w, d.h)
+			return nil
+		}
+		pos += w
+	}
+	return nil
+}
+
+// label is a console's title with a badge counting its unread lines,
+// colored by the most severe of them
+func (d *ConsoleDeck) label(c *Console, focused bool) string {
+	style := lipgloss.NewStyle().Foreground(theme.Muted).Padding(0, 1)
+	if focused {
+		style = titleBarStyle
+	}
+	if c.unread == 0 {
+		return style.Render(c.name)
+	}
+
+	count := fmt.Sprint(c.unread)
+	if c.unread > 999 {
+		count = "999+"
+	}
+	badge := lipgloss.NewStyle().Foreground(theme.Info)
+	switch c.unreadLevel {
+	case levelWarning:
+		badge = badge.Foreground(theme.Warning)
+	case levelError:
+		badge = badge.Foreground(theme.Error).Bold(true)
+	}
+	return style.Render(c.name + " " + badge.Inherit(style).Render(count))
+}
+
+// titles renders the tab bar, or a title line above each tile
+func (d *ConsoleDeck) titles(tiles map[string]layout.Rect) string {
+	if !d.tiled {
+		var labels []string
+		for i, c := range d.consoles {
+			labels = append(labels, d.label(c, i == d.current))
+		}
+		return strings.Join(labels, "")
+	}
+	line := layout.Canvas(d.w, 1)
+	for name, r := range tiles {
+		i, _ := d.index(name)
+		title := lipgloss.NewStyle().MaxWidth(r.W).Render(d.label(d.consoles[i], i == d.current))
+		line = layout.Overlay(line, title, r.X, 0)
+	}
+	return line
+}
+
+func (d *ConsoleDeck) View() string {
+	if !d.active {
+		return ""
+	}
+	tiles := d.tiles()
+	canvas := layout.Canvas(d.w, d.h)
+	canvas = layout.Overlay(canvas, d.titles(tiles), 0, 0)
+	for name, r := range tiles {
+		i, _ := d.index(name)
+		view := lipgloss.NewStyle().MaxWidth(r.W).MaxHeight(r.H).Render(d.consoles[i].View())
+		canvas = layout.Overlay(canvas, view, r.X, r.Y)
+	}
+	return canvas
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lineStream is where a console line came from
+type lineStream int
+
+const (
+	streamStdout lineStream = iota
+	streamStderr
+	// streamInput is code sent to the process, echoed in its console
+	streamInput
+)
+
+// outputLine is text read from a process or sent to it. Sent code can span
+// several lines.
+type outputLine struct {
+	stream lineStream
+	text   string
+}
+
+// lineKind classifies console lines for styling and filtering
+type lineKind int
+
+const (
+	kindOutput lineKind = iota
+	kindInput
+	kindPrompt
+	kindLate
+	kindWarning
+	kindError
+)
+
+// lineLevel is the severity of a console line
+type lineLevel int
+
+const (
+	levelInfo lineLevel = iota
+	levelWarning
+	levelError
+	numLineLevels
+)
+
+func (l lineLevel) String() string {
+	switch l {
+	case levelWarning:
+		return "warnings"
+	case levelError:
+		return "errors"
+	}
+	return "all"
+}
+
+func (k lineKind) level() lineLevel {
+	switch k {
+	case kindError:
+		return levelError
+	case kindWarning, kindLate:
+		return levelWarning
+	}
+	return levelInfo
+}
+
+// groupPosition places a line within a block of sent code
+type groupPosition int
+
+const (
+	groupNone groupPosition = iota
+	groupFirst
+	groupMiddle
+	groupLast
+)
+
+// consoleLine is a classified line of console output
+type consoleLine struct {
+	text   string
+	stream lineStream
+	kind   lineKind
+	group  groupPosition
+}
+
+func (l consoleLine) level() lineLevel {
+	return l.kind.level()
+}
+
+var (
+	// promptPattern matches ghci prompts like "tidal> ", "Prelude Sound.Tidal.Context> "
+	// and the "tidal| " continuation prompt. Prompts aren't followed by a
+	// newline, so they prefix the next line of output.
+	promptPattern = regexp.MustCompile(`^(?:\*?[\w.]+(?: \*?[\w.]+)*[>|] )+`)
+	// latePattern matches SuperDirt's reports of events arriving too late
+	latePattern    = regexp.MustCompile(`^late \d`)
+	errorPattern   = regexp.MustCompile(`(?i)(^ERROR:|\berror:|\b(exception|failed|fatal)\b|^FAILURE IN SERVER)`)
+	warningPattern = regexp.MustCompile(`(?i)(^WARNING:|\bwarning:|\bwarn(ing)?\b)`)
+)
+
+// classifyLine tags a line of process output, stripping ghci prompts. An
+// indented line following an error or warning continues it, like the
+// details ghc prints below the location of a type error.
+func classifyLine(stream lineStream, text string, prev lineKind) consoleLine {
+	if stream == streamInput {
+		return consoleLine{text: text, stream: stream, kind: kindInput}
+	}
+
+	if prompt := promptPattern.FindString(text); prompt != "" {
+		if prompt == text || strings.TrimSpace(text[len(prompt):]) == "" {
+			return consoleLine{text: strings.TrimSpace(text), stream: stream, kind: kindPrompt}
+		}
+		text = text[len(prompt):]
+	}
+
+	l := consoleLine{text: text, stream: stream, kind: kindOutput}
+	switch {
+	case latePattern.MatchString(text):
+		l.kind = kindLate
+	case errorPattern.MatchString(text):
+		l.kind = kindError
+	case warningPattern.MatchString(text):
+		l.kind = kindWarning
+	case (prev == kindError || prev == kindWarning) && text != "" &&
+		(text[0] == ' ' || text[0] == '\t' || strings.HasPrefix(text, "|")):
+		l.kind = prev
+	}
+	return l
+}
+
+// gutter marks the kind of a line in the column left of its text
+func (l consoleLine) gutter() string {
+	switch l.kind {
+	case kindInput:
+		switch l.group {
+		case groupFirst:
+			return "┌"
+		case groupMiddle:
+			return "│"
+		case groupLast:
+			return "└"
+		}
+		return "›"
+	case kindPrompt:
+		return "λ"
+	case kindLate:
+		return "◷"
+	case kindWarning:
+		return "▲"
+	case kindError:
+		return "✖"
+	}
+	if l.stream == streamStderr {
+		return "·"
+	}
+	return " "
+}
+
+// style returns the style of a line's text and gutter
+func (l consoleLine) style() lipgloss.Style {
+	switch l.kind {
+	case kindInput:
+		return lipgloss.NewStyle().Foreground(theme.Info)
+	case kindPrompt:
+		return lipgloss.NewStyle().Foreground(theme.Muted)
+	case kindLate:
+		return lipgloss.NewStyle().Foreground(theme.Warning).Faint(true)
+	case kindWarning:
+		return lipgloss.NewStyle().Foreground(theme.Warning)
+	case kindError:
+		return lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(theme.Text)
+}
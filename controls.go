@@ -0,0 +1,616 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	posc "github.com/treethought/perigee/osc"
+)
+
+// defaultCtrlPort is the port Tidal listens on for /ctrl messages
+const defaultCtrlPort = 6010
+
+// ControlDef configures a control in the controls panel
+type ControlDef struct {
+	Name string `json:"name"`
+	// Type is "f", "i" or "s" for controls read with cF, cI or cS
+	Type string  `json:"type"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	// Step is how much a key press changes the value, 1/100 of the range
+	// for floats and 1 for ints when unset
+	Step    float64 `json:"step"`
+	Default float64 `json:"default"`
+	// Values are the choices of a string control, the first being the default
+	Values []string `json:"values"`
+}
+
+type controlsKeyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	Decrease     key.Binding
+	Increase     key.Binding
+	DecreaseMore key.Binding
+	IncreaseMore key.Binding
+	Edit         key.Binding
+	Reset        key.Binding
+	Learn        key.Binding
+	Close        key.Binding
+}
+
+var defaultControlsKeyMap = controlsKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous control"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next control"),
+	),
+	Decrease: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "decrease"),
+	),
+	Increase: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "increase"),
+	),
+	DecreaseMore: key.NewBinding(
+		key.WithKeys("shift+left", "H"),
+		key.WithHelp("H", "decrease by 10 steps"),
+	),
+	IncreaseMore: key.NewBinding(
+		key.WithKeys("shift+right", "L"),
+		key.WithHelp("L", "increase by 10 steps"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "type a value"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reset to default"),
+	),
+	Learn: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "map to a MIDI control"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "close controls"),
+	),
+}
+
+type controlKind int
+
+const (
+	controlFloat controlKind = iota
+	controlInt
+	controlString
+)
+
+func parseControlKind(s string) (controlKind, bool) {
+	switch strings.ToLower(s) {
+	case "", "f", "float":
+		return controlFloat, true
+	case "i", "int":
+		return controlInt, true
+	case "s", "string":
+		return controlString, true
+	}
+	return 0, false
+}
+
+// control is a value sent to Tidal with /ctrl
+type control struct {
+	name   string
+	kind   controlKind
+	min    float64
+	max    float64
+	step   float64
+	def    float64
+	values []string
+
+	value float64
+	text  string
+	// discovered is set for controls found in the buffer
+	discovered bool
+}
+
+func newControl(d ControlDef) *control {
+	kind, _ := parseControlKind(d.Type)
+	c := &control{
+		name:   d.Name,
+		kind:   kind,
+		min:    d.Min,
+		max:    d.Max,
+		step:   d.Step,
+		def:    d.Default,
+		values: d.Values,
+	}
+	if c.max <= c.min {
+		c.max = c.min + 1
+		if kind == controlInt {
+			c.max = c.min + 127
+		}
+	}
+	if c.step <= 0 {
+		c.step = (c.max - c.min) / 100
+		if kind == controlInt {
+			c.step = 1
+		}
+	}
+	c.reset()
+	return c
+}
+
+func (c *control) reset() {
+	c.value = math.Min(math.Max(c.def, c.min), c.max)
+	c.text = ""
+	if len(c.values) > 0 {
+		c.text = c.values[0]
+	}
+}
+
+// adjust moves the value by steps, cycling through the choices of a string
+// control
+func (c *control) adjust(steps int) {
+	if c.kind == controlString {
+		if len(c.values) == 0 {
+			return
+		}
+		i := 0
+		for j, v := range c.values {
+			if v == c.text {
+				i = j
+			}
+		}
+		n := len(c.values)
+		c.text = c.values[((i+steps)%n+n)%n]
+		return
+	}
+	c.set(c.value + float64(steps)*c.step)
+}
+
+func (c *control) set(v float64) {
+	if c.kind == controlInt {
+		v = math.Round(v)
+	}
+	c.value = math.Min(math.Max(v, c.min), c.max)
+}
+
+// setText parses a typed value
+func (c *control) setText(s string) error {
+	if c.kind == controlString {
+		c.text = s
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%s needs a number", c.name)
+	}
+	c.set(v)
+	return nil
+}
+
+// arg is the value as sent in /ctrl
+func (c *control) arg() any {
+	switch c.kind {
+	case controlInt:
+		return int32(c.value)
+	case controlString:
+		return c.text
+	}
+	return float32(c.value)
+}
+
+func (c *control) valueString() string {
+	switch c.kind {
+	case controlInt:
+		return strconv.Itoa(int(c.value))
+	case controlString:
+		return strconv.Quote(c.text)
+	}
+	return strconv.FormatFloat(c.value, 'f', 3, 64)
+}
+
+// controlPattern matches controls read in Tidal code, e.g. cF 0.5 "cutoff",
+// cI 2 "step", cS "bd" "drum" and cF_ "speed"
+var controlPattern = regexp.MustCompile(`\bc([FIS])_?\s+(?:(-?[\d.]+|"[^"]*")\s+)?"([^"]+)"`)
+
+// discoverControls finds the controls read with cF, cI and cS in code
+func discoverControls(code string) []ControlDef {
+	var defs []ControlDef
+	seen := make(map[string]bool)
+	for _, m := range controlPattern.FindAllStringSubmatch(code, -1) {
+		name := m[3]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		d := ControlDef{Name: name, Type: strings.ToLower(m[1])}
+		switch d.Type {
+		case "s":
+			if def, err := strconv.Unquote(m[2]); err == nil {
+				d.Values = []string{def}
+			}
+		case "i":
+			d.Default, _ = strconv.ParseFloat(m[2], 64)
+			d.Max = max(127, d.Default*2)
+		default:
+			d.Default, _ = strconv.ParseFloat(m[2], 64)
+			d.Max = max(1, d.Default*2)
+		}
+		defs = append(defs, d)
+	}
+	return defs
+}
+
+// controlErrMsg reports a control that couldn't be sent
+type controlErrMsg struct {
+	name string
+	err  error
+}
+
+// ControlsPanel lists the configured controls and those discovered in the
+// buffer, sending each change to Tidal right away
+type ControlsPanel struct {
+	client   *posc.Client
+	controls []*control
+	// byName keeps the values of discovered controls that leave the buffer,
+	// in case they come back
+	byName map[string]*control
+	// configured is how many controls come from the config
+	configured int
+
+	cursor  int
+	offset  int
+	active  bool
+	w, h    int
+	editing bool
+	input   textinput.Model
+	message string
+}
+
+func NewControlsPanel(client *posc.Client, defs []ControlDef) *ControlsPanel {
+	input := textinput.New()
+	input.PromptStyle = lipgloss.NewStyle().Foreground(theme.Info)
+	p := &ControlsPanel{
+		client: client,
+		byName: make(map[string]*control),
+		input:  input,
+	}
+	for _, d := range defs {
+		c := newControl(d)
+		p.controls = append(p.controls, c)
+		p.byName[c.name] = c
+	}
+	p.configured = len(p.controls)
+	return p
+}
+
+// validateControls checks the controls in the config
+func validateControls(defs []ControlDef) error {
+	for _, d := range defs {
+		if d.Name == "" {
+			return fmt.Errorf("control without a name")
+		}
+		if _, ok := parseControlKind(d.Type); !ok {
+			return fmt.Errorf("control %q: unknown type %q, want f, i or s", d.Name, d.Type)
+		}
+	}
+	return nil
+}
+
+// SetDiscovered replaces the controls found in the buffer. Configured
+// controls with the same name take precedence.
+func (p *ControlsPanel) SetDiscovered(defs []ControlDef) {
+	var selected string
+	if c, ok := p.selected(); ok {
+		selected = c.name
+	}
+
+	p.controls = p.controls[:p.configured]
+	for _, d := range defs {
+		c, ok := p.byName[d.Name]
+		if ok && !c.discovered {
+			continue
+		}
+		if !ok {
+			c = newControl(d)
+			c.discovered = true
+			p.byName[d.Name] = c
+		}
+		p.controls = append(p.controls, c)
+	}
+
+	p.cursor = min(p.cursor, max(len(p.controls)-1, 0))
+	for i, c := range p.controls {
+		if c.name == selected {
+			p.cursor = i
+		}
+	}
+}
+
+func (p *ControlsPanel) selected() (*control, bool) {
+	if p.cursor >= len(p.controls) {
+		return nil, false
+	}
+	return p.controls[p.cursor], true
+}
+
+func (p *ControlsPanel) Active() bool {
+	return p.active
+}
+
+func (p *ControlsPanel) SetActive(active bool) {
+	p.active = active
+}
+
+func (p *ControlsPanel) SetSize(width, height int) {
+	p.w = width
+	p.h = height
+	p.input.Width = max(width-20, 10)
+}
+
+func (p *ControlsPanel) KeyHelp() []helpSection {
+	return []helpSection{scopeHelp("controls")}
+}
+
+// CapturingInput is true while typing a value
+func (p *ControlsPanel) CapturingInput() bool {
+	return p.editing
+}
+
+// send sends a control's value in the background
+func (p *ControlsPanel) send(c *control) tea.Cmd {
+	name, arg := c.name, c.arg()
+	return func() tea.Msg {
+		if err := p.client.SendCtrl(name, arg); err != nil {
+			return controlErrMsg{name: name, err: err}
+		}
+		return nil
+	}
+}
+
+// adjust changes the selected control by steps and sends it
+func (p *ControlsPanel) adjust(steps int) tea.Cmd {
+	c, ok := p.selected()
+	if !ok {
+		return nil
+	}
+	c.adjust(steps)
+	return p.send(c)
+}
+
+// SetNormalized sets a control to a position in its range, from 0 to 1,
+// and sends it. Controls that aren't configured are looked up in the
+// discovered ones. Unknown controls are sent the position as is.
+func (p *ControlsPanel) SetNormalized(name string, v float64, discovered []ControlDef) tea.Cmd {
+	c, ok := p.byName[name]
+	if !ok {
+		p.SetDiscovered(discovered)
+		c, ok = p.byName[name]
+	}
+	if !ok {
+		return p.send(&control{name: name, value: v})
+	}
+	if c.kind == controlString {
+		if len(c.values) > 0 {
+			c.text = c.values[min(int(v*float64(len(c.values))), len(c.values)-1)]
+		}
+	} else {
+		c.set(c.min + v*(c.max-c.min))
+	}
+	return p.send(c)
+}
+
+func (p *ControlsPanel) move(delta int) {
+	p.cursor = min(max(p.cursor+delta, 0), max(len(p.controls)-1, 0))
+}
+
+func (p *ControlsPanel) Init() tea.Cmd {
+	return nil
+}
+
+func (p *ControlsPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if p.editing {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				p.editing = false
+				p.input.Blur()
+				return p, nil
+			case "enter":
+				p.editing = false
+				p.input.Blur()
+				c, ok := p.selected()
+				if !ok {
+					return p, nil
+				}
+				if err := c.setText(strings.TrimSpace(p.input.Value())); err != nil {
+					p.message = err.Error()
+					return p, nil
+				}
+				return p, p.send(c)
+			}
+		}
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return p, cmd
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+	p.message = ""
+	switch {
+	case key.Matches(keyMsg, defaultControlsKeyMap.Up):
+		p.move(-1)
+	case key.Matches(keyMsg, defaultControlsKeyMap.Down):
+		p.move(1)
+	case key.Matches(keyMsg, defaultControlsKeyMap.Decrease):
+		return p, p.adjust(-1)
+	case key.Matches(keyMsg, defaultControlsKeyMap.Increase):
+		return p, p.adjust(1)
+	case key.Matches(keyMsg, defaultControlsKeyMap.DecreaseMore):
+		return p, p.adjust(-10)
+	case key.Matches(keyMsg, defaultControlsKeyMap.IncreaseMore):
+		return p, p.adjust(10)
+	case key.Matches(keyMsg, defaultControlsKeyMap.Reset):
+		if c, ok := p.selected(); ok {
+			c.reset()
+			return p, p.send(c)
+		}
+	case key.Matches(keyMsg, defaultControlsKeyMap.Edit):
+		c, ok := p.selected()
+		if !ok {
+			return p, nil
+		}
+		p.editing = true
+		p.input.Prompt = c.name + ": "
+		p.input.SetValue(strings.Trim(c.valueString(), `"`))
+		p.input.CursorEnd()
+		return p, p.input.Focus()
+	case key.Matches(keyMsg, defaultControlsKeyMap.Learn):
+		if c, ok := p.selected(); ok {
+			mapping := MIDIMapping{Action: "ctrl", Control: c.name}
+			return p, func() tea.Msg { return midiLearnMsg{mapping: mapping} }
+		}
+	case key.Matches(keyMsg, defaultControlsKeyMap.Close):
+		p.active = false
+	}
+	return p, nil
+}
+
+// rows is how many controls fit below the title
+func (p *ControlsPanel) rows() int {
+	_, fh := consoleStyle.GetFrameSize()
+	return max(p.h-fh-2, 1)
+}
+
+// scrollToCursor keeps the selected control in view
+func (p *ControlsPanel) scrollToCursor() {
+	rows := p.rows()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+	p.offset = min(p.offset, max(len(p.controls)-rows, 0))
+}
+
+// Scroll adjusts the selected control, the wheel turning it like a knob
+func (p *ControlsPanel) Scroll(delta int) tea.Cmd {
+	if p.editing {
+		return nil
+	}
+	return p.adjust(-delta)
+}
+
+// Click selects a control and sets it to the position clicked on its bar
+func (p *ControlsPanel) Click(x, y int, double bool) tea.Cmd {
+	fw, fh := consoleStyle.GetFrameSize()
+	row := y - fh/2 - 1
+	if row < 0 || p.offset+row >= len(p.controls) {
+		return nil
+	}
+	p.cursor = p.offset + row
+	c := p.controls[p.cursor]
+
+	nameW, barW := p.columns()
+	barX := x - fw/2 - nameW - 1
+	if c.kind == controlString || barX < 0 || barX >= barW {
+		if double {
+			c.adjust(1)
+			return p.send(c)
+		}
+		return nil
+	}
+	c.set(c.min + (c.max-c.min)*float64(barX)/float64(max(barW-1, 1)))
+	return p.send(c)
+}
+
+// columns returns the width of the names and the bars
+func (p *ControlsPanel) columns() (int, int) {
+	nameW := 4
+	for _, c := range p.controls {
+		nameW = max(nameW, ansi.StringWidth(c.name))
+	}
+	fw, _ := consoleStyle.GetFrameSize()
+	inner := p.w - fw
+	nameW = min(nameW, max(inner/3, 4))
+	// the bar sits between the name and a value column of 9 cells
+	return nameW, max(inner-nameW-1-1-9, 4)
+}
+
+func (p *ControlsPanel) renderControl(c *control, selected bool) string {
+	nameW, barW := p.columns()
+	name := ansi.Truncate(c.name, nameW, "…")
+	name += strings.Repeat(" ", nameW-ansi.StringWidth(name))
+
+	var bar string
+	if c.kind == controlString {
+		bar = strings.Repeat(" ", barW)
+		if len(c.values) > 1 {
+			bar = ansi.Truncate(strings.Join(c.values, " "), barW, "…")
+			bar += strings.Repeat(" ", barW-ansi.StringWidth(bar))
+		}
+	} else {
+		filled := int(math.Round(float64(barW) * (c.value - c.min) / (c.max - c.min)))
+		bar = lipgloss.NewStyle().Foreground(theme.Accent).Render(strings.Repeat("█", filled)) +
+			completionStyle.Render(strings.Repeat("░", barW-filled))
+	}
+
+	value := ansi.Truncate(c.valueString(), 9, "…")
+	style := finderItemStyle
+	if selected {
+		style = finderSelectedStyle
+	}
+	return style.Render(name) + " " + bar + " " + style.Render(fmt.Sprintf("%9s", value))
+}
+
+func (p *ControlsPanel) View() string {
+	if !p.active {
+		return ""
+	}
+	p.scrollToCursor()
+	fw, _ := consoleStyle.GetFrameSize()
+
+	host, port := p.client.Addr()
+	title := titleBarStyle.Render("Controls") + completionStyle.Render(fmt.Sprintf(" /ctrl → %s:%d", host, port))
+	lines := []string{ansi.Truncate(title, p.w-fw, "…")}
+	if len(p.controls) == 0 {
+		lines = append(lines, completionStyle.Render("no controls, add them to the config or use cF \"name\" in code"))
+	}
+	for i := p.offset; i < min(p.offset+p.rows(), len(p.controls)); i++ {
+		lines = append(lines, p.renderControl(p.controls[i], i == p.cursor))
+	}
+	for len(lines) < p.rows()+1 {
+		lines = append(lines, "")
+	}
+
+	switch {
+	case p.editing:
+		lines = append(lines, p.input.View())
+	case p.message != "":
+		lines = append(lines, diagnosticStyle.Render(p.message))
+	default:
+		if c, ok := p.selected(); ok && c.discovered {
+			lines = append(lines, completionStyle.Render("found in buffer"))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+	return consoleStyle.Width(p.w - 2).Render(strings.Join(lines, "\n"))
+}
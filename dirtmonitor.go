@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dirtWindow is how long a problem counts towards the health indicator
+const dirtWindow = 10 * time.Second
+
+var (
+	// dirtLatePattern matches SuperDirt's "late 0.0123" warnings, the
+	// number being how late the message arrived in seconds
+	dirtLatePattern    = regexp.MustCompile(`^late (\d+(?:\.\d+)?)`)
+	dirtMissingPattern = regexp.MustCompile(`no synth or sample named '([^']+)'`)
+	dirtXrunPattern    = regexp.MustCompile(`(?i)\bxrun`)
+	dirtCPUPattern     = regexp.MustCompile(`(?i)\b(avg|peak)\s*cpu\W*(\d+(?:\.\d+)?)`)
+)
+
+// dirtHealth summarizes how SuperDirt is coping
+type dirtHealth int
+
+const (
+	dirtOK dirtHealth = iota
+	dirtStrained
+	dirtFailing
+)
+
+// dirtMonitor counts the problems SuperDirt and the server report in the
+// sclang output. Totals are kept until reset, the health only considers
+// the problems of the last dirtWindow.
+type dirtMonitor struct {
+	late int
+	// lateMax is the latest a message arrived, in seconds
+	lateMax float64
+	xruns   int
+	// missing counts the errors for each unknown sound name
+	missing map[string]int
+
+	avgCPU, peakCPU float64
+	hasCPU          bool
+
+	// recent holds when each late message, xrun and missing sound was seen
+	recentLate    []time.Time
+	recentXrun    []time.Time
+	recentMissing []time.Time
+}
+
+func newDirtMonitor() *dirtMonitor {
+	return &dirtMonitor{missing: make(map[string]int)}
+}
+
+// Observe counts a line of sclang output. It returns the sound name of a
+// missing sample error the first time the name is reported.
+func (m *dirtMonitor) Observe(text string, now time.Time) (newMissing string) {
+	if match := dirtLatePattern.FindStringSubmatch(text); match != nil {
+		m.late++
+		if late, err := strconv.ParseFloat(match[1], 64); err == nil {
+			m.lateMax = max(m.lateMax, late)
+		}
+		m.recentLate = append(m.recentLate, now)
+		return ""
+	}
+	if match := dirtMissingPattern.FindStringSubmatch(text); match != nil {
+		name := match[1]
+		m.missing[name]++
+		m.recentMissing = append(m.recentMissing, now)
+		if m.missing[name] == 1 {
+			return name
+		}
+		return ""
+	}
+	if dirtXrunPattern.MatchString(text) {
+		m.xruns++
+		m.recentXrun = append(m.recentXrun, now)
+		return ""
+	}
+	for _, match := range dirtCPUPattern.FindAllStringSubmatch(text, -1) {
+		cpu, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(match[1], "avg") {
+			m.avgCPU = cpu
+		} else {
+			m.peakCPU = cpu
+		}
+		m.hasCPU = true
+	}
+	return ""
+}
+
+// SetCPU records the server's CPU load in percent. The load counts towards
+// the health, the server status shows it.
+func (m *dirtMonitor) SetCPU(avg, peak float64) {
+	m.avgCPU, m.peakCPU = avg, peak
+	m.hasCPU = true
+}
+
+// ClearCPU forgets the CPU load once the server stops reporting it, so a
+// stale load doesn't keep counting towards the health
+func (m *dirtMonitor) ClearCPU() {
+	m.avgCPU, m.peakCPU = 0, 0
+	m.hasCPU = false
+}
+
+// Missing returns the unknown sound names, most reported first
+func (m *dirtMonitor) Missing() []string {
+	names := make([]string, 0, len(m.missing))
+	for name := range m.missing {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if m.missing[names[i]] != m.missing[names[j]] {
+			return m.missing[names[i]] > m.missing[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// MissingCount returns how often a sound name was reported missing
+func (m *dirtMonitor) MissingCount(name string) int {
+	return m.missing[name]
+}
+
+func (m *dirtMonitor) Reset() {
+	*m = *newDirtMonitor()
+}
+
+// dropExpired drops the times older than the window
+func dropExpired(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > dirtWindow {
+		i++
+	}
+	return times[i:]
+}
+
+// Health rates the recent problems. A few late messages or an xrun strain
+// the server, a burst of late messages, missing sounds or a saturated CPU
+// mean events are being lost.
+func (m *dirtMonitor) Health(now time.Time) dirtHealth {
+	m.recentLate = dropExpired(m.recentLate, now)
+	m.recentXrun = dropExpired(m.recentXrun, now)
+	m.recentMissing = dropExpired(m.recentMissing, now)
+
+	switch {
+	case len(m.recentLate) >= 5, len(m.recentMissing) > 0, m.hasCPU && m.peakCPU >= 95:
+		return dirtFailing
+	case len(m.recentLate) > 0, len(m.recentXrun) > 0, m.hasCPU && m.peakCPU >= 80:
+		return dirtStrained
+	}
+	return dirtOK
+}
+
+// Indicator renders the health and counters compactly for the status line
+func (m *dirtMonitor) Indicator(now time.Time) string {
+	dot := lipgloss.NewStyle().Foreground(theme.Success)
+	switch m.Health(now) {
+	case dirtStrained:
+		dot = dot.Foreground(theme.Warning)
+	case dirtFailing:
+		dot = dot.Foreground(theme.Error)
+	}
+
+	var parts []string
+	if m.late > 0 {
+		parts = append(parts, fmt.Sprintf("late %d (%.3fs)", m.late, m.lateMax))
+	}
+	if len(m.missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing %d", len(m.missing)))
+	}
+	if m.xruns > 0 {
+		parts = append(parts, fmt.Sprintf("xruns %d", m.xruns))
+	}
+	indicator := completionStyle.Render("dirt ") + dot.Render("●")
+	if len(parts) > 0 {
+		indicator += completionStyle.Render(" " + strings.Join(parts, " "))
+	}
+	return indicator
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kujtimiihoxha/vimtea"
+	"golang.design/x/clipboard"
 )
 
 var defaultFile = "perigee.tidal"
@@ -23,11 +26,67 @@ func sentMsgCmd(s string) tea.Cmd {
 	}
 }
 
+type editorKeyMap struct {
+	Comment   key.Binding
+	PrevFile  key.Binding
+	SendBlock key.Binding
+	Hush      key.Binding
+	Save      key.Binding
+}
+
+var defaultEditorKeyMap = editorKeyMap{
+	Comment: key.NewBinding(
+		key.WithKeys("ctrl+_"),
+		key.WithHelp("ctrl+_", "comment line or selection"),
+	),
+	PrevFile: key.NewBinding(
+		key.WithKeys("ctrl+^"),
+		key.WithHelp("ctrl+^", "previous file"),
+	),
+	SendBlock: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("ctrl+e", "send block to tidal"),
+	),
+	Hush: key.NewBinding(
+		key.WithKeys("ctrl+h"),
+		key.WithHelp("ctrl+h", "hush"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save file"),
+	),
+}
+
 type Editor struct {
 	e           vimtea.Editor
 	send        sendFunc
 	currentFile string
 	prevFile    string
+	diskText    string // file content as last loaded or saved
+	width       int
+
+	banks      map[string]int
+	synths     []string
+	completion *sampleCompletion
+	diags      []sampleDiagnostic
+	lastText   string
+
+	// bindings are the vimtea bindings added by perigee, listed in the help overlay
+	bindings []vimtea.KeyBinding
+	// controls are the controls read in the buffer, found when controlsText changes
+	controls     []ControlDef
+	controlsText string
+	// blocks and scenes are the named blocks in the buffer, found when blocksText changes
+	blocks     []namedBlock
+	scenes     []scene
+	blocksText string
+	// setList is the set list in the buffer, found when setListText changes
+	setList     []setEntry
+	setListText string
+	// indicators renders app state shown at the right of the info line
+	indicators func() string
+	// snippet holds the placeholders of the snippet being filled in
+	snippet *snippetSession
 }
 
 func NewEditor(send sendFunc) *Editor {
@@ -35,11 +94,7 @@ func NewEditor(send sendFunc) *Editor {
 		currentFile: defaultFile,
 		send:        send,
 		e: vimtea.NewEditor(
-			vimtea.WithFileName("tidal.hs"),
-			vimtea.WithDefaultSyntaxTheme("autumn"),
-			vimtea.WithStatusStyle(
-				lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Bold(true),
-			),
+			append(editorThemeOptions(), vimtea.WithFileName("tidal.hs"))...,
 		),
 	}
 
@@ -49,101 +104,106 @@ func NewEditor(send sendFunc) *Editor {
 		}
 	})
 
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+_",
-		Mode:        vimtea.ModeNormal,
-		Description: "Comment line",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			return m.comment(b)
-		},
+	m.addBinding(defaultEditorKeyMap.Comment, vimtea.ModeNormal, "Comment line", m.comment)
+	m.addBinding(defaultEditorKeyMap.Comment, vimtea.ModeVisual, "Comment selected region", m.comment)
+	m.addBinding(defaultEditorKeyMap.PrevFile, vimtea.ModeNormal, "Previous file", func(b vimtea.Buffer) tea.Cmd {
+		if m.prevFile == "" {
+			return vimtea.SetStatusMsg("No previous file")
+		}
+		return m.load(m.prevFile)
 	})
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+_",
-		Mode:        vimtea.ModeVisual,
-		Description: "Comment selected region",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			return m.comment(b)
-		},
+	m.addBinding(defaultEditorKeyMap.SendBlock, vimtea.ModeNormal, "Send block to tidal", func(b vimtea.Buffer) tea.Cmd {
+		content, _, _ := m.CurrentBlock()
+		if strings.TrimSpace(content) == "" {
+			return vimtea.SetStatusMsg("No block under the cursor")
+		}
+
+		if err := m.send(content); err != nil {
+			return vimtea.SetStatusMsg(fmt.Sprintf("Error sending command: %v", err))
+		}
+		return tea.Batch(
+			vimtea.SetStatusMsg("sent!"),
+			sentMsgCmd(content),
+		)
 	})
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+^",
-		Mode:        vimtea.ModeNormal,
-		Description: "Previous file",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			if m.prevFile == "" {
-				return vimtea.SetStatusMsg("No previous file")
-			}
-			return m.load(m.prevFile)
-		},
+	m.addBinding(defaultEditorKeyMap.Hush, vimtea.ModeNormal, "Hush", func(b vimtea.Buffer) tea.Cmd {
+		if err := m.send("hush"); err != nil {
+			return vimtea.SetStatusMsg(fmt.Sprintf("Error sending command: %v", err))
+		}
+		return vimtea.SetStatusMsg("Hushed!")
 	})
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+e",
-		Mode:        vimtea.ModeNormal,
-		Description: "Send block to tidal",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			cursor := m.e.GetCursor()
-
-			lines := b.Lines()
-			if len(lines) == 0 {
-				return vimtea.SetStatusMsg("Buffer is empty")
-			}
-
-			// Initialize begin and end to cursor position
-			begin := cursor.Row
-			end := cursor.Row
-
-			// Find the beginning of the block (go up until empty line or start)
-			for i := cursor.Row - 1; i >= 0; i-- {
-				if strings.TrimSpace(lines[i]) == "" {
-					break
-				}
-				begin = i
-			}
-
-			// Find the end of the block (go down until empty line or end)
-			for i := cursor.Row + 1; i < len(lines); i++ {
-				if strings.TrimSpace(lines[i]) == "" {
-					break
-				}
-				end = i
-			}
-
-			// Extract lines for the block (inclusive)
-			blockLines := lines[begin : end+1]
-			content := strings.Join(blockLines, "\n")
-
-			if err := m.send(content); err != nil {
-				return vimtea.SetStatusMsg(fmt.Sprintf("Error sending command: %v", err))
-			}
-			return tea.Batch(
-				vimtea.SetStatusMsg("sent!"),
-				sentMsgCmd(content),
-			)
-		},
+	m.addBinding(defaultEditorKeyMap.Save, vimtea.ModeNormal, "Save file", func(b vimtea.Buffer) tea.Cmd {
+		return m.save(m.currentFile, b.Text())
 	})
 
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+h",
-		Mode:        vimtea.ModeNormal,
-		Description: "Hush",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			if err := m.send("hush"); err != nil {
-				return vimtea.SetStatusMsg(fmt.Sprintf("Error sending command: %v", err))
-			}
-			return vimtea.SetStatusMsg("Hushed!")
-		},
-	})
+	return m
+}
 
-	m.e.AddBinding(vimtea.KeyBinding{
-		Key:         "ctrl+s",
-		Mode:        vimtea.ModeNormal,
-		Description: "Save file",
-		Handler: func(b vimtea.Buffer) tea.Cmd {
-			return m.save(m.currentFile, b.Text())
-		},
-	})
+// addBinding registers a vimtea binding for each key of b
+func (m *Editor) addBinding(b key.Binding, mode vimtea.EditorMode, desc string, handler func(vimtea.Buffer) tea.Cmd) {
+	if !b.Enabled() {
+		return
+	}
+	for _, k := range b.Keys() {
+		kb := vimtea.KeyBinding{
+			Key:         k,
+			Mode:        mode,
+			Description: desc,
+			Handler:     handler,
+		}
+		m.e.AddBinding(kb)
+		m.bindings = append(m.bindings, kb)
+	}
+}
 
-	return m
+// KeyHelp lists perigee's editor bindings with their vimtea descriptions,
+// then the snippet placeholder keys
+func (m *Editor) KeyHelp() []helpSection {
+	section := helpSection{title: "Editor"}
+	for _, kb := range m.bindings {
+		desc := kb.Description
+		if kb.Mode == vimtea.ModeVisual {
+			desc += " (visual)"
+		}
+		section.bindings = append(section.bindings, key.NewBinding(
+			key.WithKeys(kb.Key),
+			key.WithHelp(kb.Key, desc),
+		))
+	}
+	return []helpSection{section, scopeHelp("snippet")}
+}
+
+// CurrentBlock returns the block around the cursor, the lines between the
+// nearest blank lines, with its first and last rows
+func (m *Editor) CurrentBlock() (string, int, int) {
+	lines := m.e.GetBuffer().Lines()
+	if len(lines) == 0 {
+		return "", 0, 0
+	}
+	row := min(m.e.GetCursor().Row, len(lines)-1)
+
+	// Initialize begin and end to cursor position
+	begin := row
+	end := row
+
+	// Find the beginning of the block (go up until empty line or start)
+	for i := row - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+		begin = i
+	}
+
+	// Find the end of the block (go down until empty line or end)
+	for i := row + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+		end = i
+	}
+
+	// Extract lines for the block (inclusive)
+	return strings.Join(lines[begin:end+1], "\n"), begin, end
 }
 
 func (m *Editor) comment(b vimtea.Buffer) tea.Cmd {
@@ -174,9 +234,52 @@ func (m *Editor) comment(b vimtea.Buffer) tea.Cmd {
 	return nil
 }
 
+// insertAtCursor inserts text after the cursor, like vim's p,
+// and leaves the cursor on the last inserted character. The buffer records
+// an undo state before inserting, so u removes the text again.
+func (m *Editor) insertAtCursor(text string) tea.Cmd {
+	if text == "" {
+		return nil
+	}
+	b := m.e.GetBuffer()
+	cursor := m.e.GetCursor()
+	col := m.insertCol()
+	b.InsertAt(cursor.Row, col, text)
+
+	lines := strings.Split(text, "\n")
+	row := cursor.Row + len(lines) - 1
+	endCol := len(lines[len(lines)-1])
+	if len(lines) == 1 {
+		endCol += col
+	}
+	if m.e.GetMode() != vimtea.ModeInsert {
+		endCol--
+	}
+	m.e.SetCursor(row, max(endCol, 0))
+	return m.e.SetStatusMessage(fmt.Sprintf("inserted: %s", text))
+}
+
+// insertCol returns the column text is inserted at: after the cursor in
+// normal mode and at it in insert mode
+func (m *Editor) insertCol() int {
+	b := m.e.GetBuffer()
+	cursor := m.e.GetCursor()
+	col := cursor.Col
+	if m.e.GetMode() != vimtea.ModeInsert && b.LineLength(cursor.Row) > 0 {
+		col++
+	}
+	return min(col, b.LineLength(cursor.Row))
+}
+
+// yank copies text into the register used by the editor's paste commands
+func (m *Editor) yank(text string) tea.Cmd {
+	clipboard.Write(clipboard.FmtText, []byte(text))
+	return m.e.SetStatusMessage(fmt.Sprintf("yanked: %s", text))
+}
+
 func (m *Editor) load(fname string) tea.Cmd {
 	return func() tea.Msg {
-		if m.currentFile != "" {
+		if m.currentFile != "" && m.currentFile != fname {
 			m.prevFile = m.currentFile
 		}
 		content, err := os.ReadFile(fname)
@@ -187,6 +290,8 @@ func (m *Editor) load(fname string) tea.Cmd {
 		m.e.GetBuffer().Clear()
 		m.e.GetBuffer().InsertAt(0, 0, string(content))
 		m.currentFile = fname
+		m.diskText = string(content)
+		m.checkSamples(false)
 		return m.e.SetStatusMessage(fname)
 	}
 }
@@ -198,13 +303,119 @@ func (m *Editor) save(fname string, content string) tea.Cmd {
 			log.Printf("Error saving file %s: %v", fname, err)
 			return m.e.SetStatusMessage(fmt.Sprintf("Error saving file: %v", err))
 		}
+		if fname == m.currentFile {
+			m.diskText = content
+		}
 		log.Printf("File %s saved successfully", fname)
 		return m.e.SetStatusMessage(fmt.Sprintf("saved: %s", fname))
 	}
 }
 
+// SetSampleBanks sets the sample count of every bank, used for completion and diagnostics
+func (m *Editor) SetSampleBanks(banks map[string]int) {
+	m.banks = banks
+	m.checkSamples(true)
+}
+
+// SetSynths sets extra sound names that are synths rather than sample banks
+func (m *Editor) SetSynths(synths []string) {
+	m.synths = synths
+	m.checkSamples(true)
+}
+
+// checkSamples re-runs sample diagnostics when the buffer has changed
+func (m *Editor) checkSamples(force bool) {
+	text := m.e.GetBuffer().Text()
+	if !force && text == m.lastText {
+		return
+	}
+	m.lastText = text
+	m.diags = sampleDiagnostics(m.e.GetBuffer().Lines(), m.banks, m.synths)
+}
+
+// updateCompletion offers bank names when the cursor is inside an s/sound pattern
+func (m *Editor) updateCompletion() {
+	m.completion = nil
+	if m.e.GetMode() != vimtea.ModeInsert || len(m.banks) == 0 {
+		return
+	}
+	cursor := m.e.GetCursor()
+	lines := m.e.GetBuffer().Lines()
+	if cursor.Row >= len(lines) {
+		return
+	}
+	word, start, ok := soundWordAt(lines[cursor.Row], cursor.Col)
+	if !ok {
+		return
+	}
+	items := completeSample(m.banks, word)
+	if len(items) == 0 {
+		return
+	}
+	m.completion = &sampleCompletion{
+		items: items,
+		row:   cursor.Row,
+		start: start,
+		word:  word,
+	}
+}
+
+// acceptCompletion replaces the partial word with the selected bank
+func (m *Editor) acceptCompletion() {
+	c := m.completion
+	m.completion = nil
+	if !c.Open() || strings.Contains(c.word, ":") {
+		return
+	}
+	item := c.Selected()
+	b := m.e.GetBuffer()
+	if c.word != "" {
+		b.DeleteAt(c.row, c.start, c.row, c.start+len(c.word)-1)
+	}
+	b.InsertAt(c.row, c.start, item.bank)
+	m.e.SetCursor(c.row, c.start+len(item.bank))
+}
+
+// FilePath returns the absolute path of the open file
+func (m *Editor) FilePath() string {
+	path, err := filepath.Abs(m.currentFile)
+	if err != nil {
+		return m.currentFile
+	}
+	return path
+}
+
+// ChangedOnDisk reports whether the open file differs from what was last loaded or saved
+func (m *Editor) ChangedOnDisk() bool {
+	content, err := os.ReadFile(m.currentFile)
+	if err != nil {
+		return false
+	}
+	return string(content) != m.diskText
+}
+
+// Modified reports whether the buffer has changes that weren't saved
+func (m *Editor) Modified() bool {
+	return m.e.GetBuffer().Text() != m.diskText
+}
+
+// Reload loads the open file again, discarding unsaved changes
+func (m *Editor) Reload() tea.Cmd {
+	cursor := m.e.GetCursor()
+	return tea.Sequence(
+		m.load(m.currentFile),
+		func() tea.Msg {
+			m.e.SetCursor(cursor.Row, cursor.Col)
+			return nil
+		},
+		m.e.SetStatusMessage(fmt.Sprintf("reloaded: %s", m.currentFile)),
+	)
+}
+
 func (m *Editor) SetSize(width, height int) (vimtea.Editor, tea.Cmd) {
-	ed, cmd := m.e.SetSize(width, height)
+	m.width = width
+	// reserve a line for completions and diagnostics
+	ed, cmd := m.e.SetSize(width, height-1)
 	m.e = ed.(vimtea.Editor)
 	return m.e, cmd
 }
@@ -213,13 +424,85 @@ func (m *Editor) Init() tea.Cmd {
 	return m.e.Init()
 }
 func (m *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && m.completion.Open() {
+		switch msg.String() {
+		case "tab":
+			m.acceptCompletion()
+			m.checkSamples(false)
+			return m, nil
+		case "ctrl+n", "down":
+			m.completion.Move(1)
+			return m, nil
+		case "ctrl+p", "up":
+			m.completion.Move(-1)
+			return m, nil
+		}
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.updateSnippet(msg) {
+		m.checkSamples(false)
+		return m, nil
+	}
+
 	_, cmd := m.e.Update(msg)
+	if m.e.GetMode() != vimtea.ModeInsert {
+		m.snippet = nil
+	}
 	// if model != nil {
 	// 	m.e = model.(vimtea.Editor)
 	// }
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.updateCompletion()
+		m.checkSamples(false)
+	}
 	return m, cmd
 }
 
+// Controls returns the controls read with cF, cI and cS in the buffer
+func (m *Editor) Controls() []ControlDef {
+	text := m.e.GetBuffer().Text()
+	if text != m.controlsText {
+		m.controlsText = text
+		m.controls = discoverControls(text)
+	}
+	return m.controls
+}
+
+// SetIndicators sets the function rendering the indicators at the right
+// of the info line
+func (m *Editor) SetIndicators(f func() string) {
+	m.indicators = f
+}
+
+func (m *Editor) infoView() string {
+	right := ""
+	if m.indicators != nil {
+		right = m.indicators()
+	}
+	width := m.width
+	if right != "" {
+		width = max(width-lipgloss.Width(right)-1, 1)
+	}
+
+	var left string
+	if m.completion.Open() {
+		left = m.completion.View(width)
+	} else if m.snippet != nil {
+		left = m.snippetView(width)
+	} else {
+		left = diagnosticsView(m.diags, m.e.GetCursor().Row, width)
+	}
+	if right == "" {
+		return left
+	}
+	gap := max(m.width-lipgloss.Width(left)-lipgloss.Width(right), 1)
+	return left + strings.Repeat(" ", gap) + right
+}
+
 func (m *Editor) View() string {
-	return m.e.View()
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.e.View(),
+		m.infoView(),
+	)
 }
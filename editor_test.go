@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runCmd feeds a command's messages back to the editor, as the program would
+func runCmd(m *Editor, cmd tea.Cmd) {
+	for cmd != nil {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				runCmd(m, c)
+			}
+			return
+		}
+		_, cmd = m.Update(msg)
+	}
+}
+
+func pressKey(m *Editor, msg tea.KeyMsg) {
+	_, cmd := m.Update(msg)
+	runCmd(m, cmd)
+}
+
+func TestInsertUndo(t *testing.T) {
+	const text = `d1 $ s "bd"`
+	tests := []struct {
+		name   string
+		insert func(m *Editor) tea.Cmd
+		want   string
+	}{
+		{
+			name:   "sample name",
+			insert: func(m *Editor) tea.Cmd { return m.insertAtCursor("sn") },
+			want:   `dsn1 $ s "bd"`,
+		},
+		{
+			name:   "snippet",
+			insert: func(m *Editor) tea.Cmd { return m.insertSnippet("# ${1:fast 2} $0") },
+			want:   `d# fast 2 1 $ s "bd"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewEditor(func(string) error { return nil })
+			m.e.GetBuffer().InsertAt(0, 0, text)
+
+			runCmd(m, tt.insert(m))
+			if got := m.e.GetBuffer().Text(); got != tt.want {
+				t.Fatalf("after insert got %q, want %q", got, tt.want)
+			}
+			pressKey(m, tea.KeyMsg{Type: tea.KeyEsc})
+			pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+			if got := m.e.GetBuffer().Text(); got != text {
+				t.Errorf("after undo got %q, want %q", got, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// defaultBarCycles is how many cycles make a bar unless configured
+	defaultBarCycles = 4
+	// evalQueueMaxTick is the longest wait between checks of the clock
+	// while evaluations are queued, which also refreshes the countdown
+	evalQueueMaxTick = 250 * time.Millisecond
+)
+
+// quantize is when queued evaluations are released
+type quantize int
+
+const (
+	quantizeOff quantize = iota
+	quantizeCycle
+	quantizeBar
+)
+
+func (q quantize) String() string {
+	switch q {
+	case quantizeCycle:
+		return "cycle"
+	case quantizeBar:
+		return "bar"
+	}
+	return "off"
+}
+
+// parseQuantize reads the quantize setting, off when empty
+func parseQuantize(s string) (quantize, error) {
+	switch s {
+	case "", "off":
+		return quantizeOff, nil
+	case "cycle":
+		return quantizeCycle, nil
+	case "bar":
+		return quantizeBar, nil
+	}
+	return quantizeOff, fmt.Errorf("unknown quantize %q, want off, cycle or bar", s)
+}
+
+// validateQuantize checks the quantize settings in the config
+func validateQuantize(cfg *Config) error {
+	if cfg.BarCycles < 0 {
+		return fmt.Errorf("bar_cycles must be positive")
+	}
+	_, err := parseQuantize(cfg.Quantize)
+	return err
+}
+
+// evalQueueMsg wakes the app to schedule newly queued evaluations
+type evalQueueMsg struct{}
+
+// evalReleaseMsg checks the clock for evaluations to release
+type evalReleaseMsg struct{}
+
+func listenEvalQueue(ch chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-ch
+		return evalQueueMsg{}
+	}
+}
+
+// queuedEval is code waiting for a cycle
+type queuedEval struct {
+	code  string
+	cycle float64
+}
+
+// evalQueue holds evaluations until the next cycle or bar boundary, so
+// code like setcps or once lands on the beat. hush is never queued and
+// drops what's pending.
+type evalQueue struct {
+	clock     *cycleClock
+	send      sendFunc
+	mode      quantize
+	barCycles int
+
+	pending []queuedEval
+	// wake is signaled when code is queued, as Send can't return a command
+	wake    chan struct{}
+	ticking bool
+}
+
+func newEvalQueue(clock *cycleClock, send sendFunc, mode quantize, barCycles int) *evalQueue {
+	if barCycles == 0 {
+		barCycles = defaultBarCycles
+	}
+	return &evalQueue{
+		clock:     clock,
+		send:      send,
+		mode:      mode,
+		barCycles: barCycles,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// boundary returns the first cycle or bar boundary after a cycle
+func (q *evalQueue) boundary(cycle float64) float64 {
+	if q.mode == quantizeBar {
+		bar := float64(q.barCycles)
+		return (math.Floor(cycle/bar) + 1) * bar
+	}
+	return math.Floor(cycle) + 1
+}
+
+// StartsAt returns the cycle code sent at a cycle starts on: the boundary
+// it's released at, or the start of the current cycle when quantizing is off
+func (q *evalQueue) StartsAt(cycle float64) float64 {
+	if q.mode == quantizeOff {
+		return math.Floor(cycle)
+	}
+	return q.boundary(cycle)
+}
+
+// Send queues code for the next boundary, or sends it right away when
+// quantizing is off
+func (q *evalQueue) Send(code string) error {
+	if strings.TrimSpace(code) == "hush" {
+		q.pending = nil
+		return q.send(code)
+	}
+	if q.mode == quantizeOff {
+		return q.send(code)
+	}
+	q.pending = append(q.pending, queuedEval{
+		code:  code,
+		cycle: q.boundary(q.clock.Cycle(time.Now())),
+	})
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pending returns how many evaluations are queued
+func (q *evalQueue) Pending() int {
+	return len(q.pending)
+}
+
+// Cancel drops the queued evaluations, returning how many there were
+func (q *evalQueue) Cancel() int {
+	n := len(q.pending)
+	q.pending = nil
+	return n
+}
+
+// Mode returns when evaluations are released
+func (q *evalQueue) Mode() quantize {
+	return q.mode
+}
+
+// NextMode switches between off, cycle and bar. Turning quantizing off
+// sends what's queued.
+func (q *evalQueue) NextMode() (quantize, error) {
+	q.mode = (q.mode + 1) % 3
+	if q.mode != quantizeOff {
+		return q.mode, nil
+	}
+	pending := q.pending
+	q.pending = nil
+	for _, e := range pending {
+		if err := q.send(e.code); err != nil {
+			return q.mode, err
+		}
+	}
+	return q.mode, nil
+}
+
+// tick checks the clock again at the first release, or sooner to refresh
+// the countdown
+func (q *evalQueue) tick() tea.Cmd {
+	if q.ticking || len(q.pending) == 0 {
+		return nil
+	}
+	q.ticking = true
+	d := q.clock.Until(q.next().cycle, time.Now())
+	if d > evalQueueMaxTick {
+		d = evalQueueMaxTick
+	}
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return evalReleaseMsg{}
+	})
+}
+
+// next returns the first evaluation to be released
+func (q *evalQueue) next() queuedEval {
+	next := q.pending[0]
+	for _, e := range q.pending[1:] {
+		if e.cycle < next.cycle {
+			next = e
+		}
+	}
+	return next
+}
+
+// release sends the evaluations whose boundary has been reached, in the
+// order they were queued. Evaluations waiting beyond the next boundary were
+// queued before the clock jumped back, e.g. when Tidal restarted, and are
+// moved to the next boundary.
+func (q *evalQueue) release() error {
+	q.ticking = false
+	cycle := q.clock.Cycle(time.Now())
+	boundary := q.boundary(cycle)
+	var err error
+	var waiting []queuedEval
+	for _, e := range q.pending {
+		e.cycle = math.Min(e.cycle, boundary)
+		if e.cycle > cycle {
+			waiting = append(waiting, e)
+			continue
+		}
+		if sendErr := q.send(e.code); sendErr != nil {
+			err = sendErr
+		}
+	}
+	q.pending = waiting
+	return err
+}
+
+// Indicator shows the quantize mode and the countdown to the next release
+// for the status line
+func (q *evalQueue) Indicator() string {
+	if q.mode == quantizeOff && len(q.pending) == 0 {
+		return ""
+	}
+	s := completionStyle.Render("q:" + q.mode.String())
+	if len(q.pending) == 0 {
+		return s
+	}
+	next := q.next()
+	wait := q.clock.Until(next.cycle, time.Now())
+	pending := lipgloss.NewStyle().Foreground(theme.Warning).Bold(true)
+	return s + " " + pending.Render(fmt.Sprintf("⧗ %d @%d in %.1fs", len(q.pending), int(next.cycle), wait.Seconds()))
+}
+
+// queueTidal sends code to Tidal through the queue, reporting failures in
+// the status line
+func (a *App) queueTidal(code string) tea.Cmd {
+	if err := a.queue.Send(code); err != nil {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err))
+	}
+	return nil
+}
+
+// cancelQueued drops the queued evaluations
+func (a *App) cancelQueued() tea.Cmd {
+	n := a.queue.Cancel()
+	if n == 0 {
+		return a.editor.e.SetStatusMessage("nothing queued")
+	}
+	return a.editor.e.SetStatusMessage(fmt.Sprintf("canceled %d queued evaluations", n))
+}
+
+// cycleQuantize switches when evaluations are released
+func (a *App) cycleQuantize() tea.Cmd {
+	mode, err := a.queue.NextMode()
+	if err != nil {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err))
+	}
+	return a.editor.e.SetStatusMessage("quantize: " + mode.String())
+}
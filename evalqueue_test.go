@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	posc "github.com/treethought/perigee/osc"
+)
+
+func TestEvalQueueRelease(t *testing.T) {
+	tests := []struct {
+		name string
+		// queue lists the cycle the clock is at when each code is queued
+		queue []float64
+		// at is the cycle the clock is at on release
+		at   float64
+		want []string
+		left int
+	}{
+		{
+			name:  "before the boundary",
+			queue: []float64{10.2, 10.5},
+			at:    10.9,
+			left:  2,
+		},
+		{
+			name:  "at the boundary",
+			queue: []float64{10.2, 10.5},
+			at:    11,
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "clock jumped back",
+			queue: []float64{500.5},
+			at:    3.2,
+			left:  1,
+		},
+		{
+			name:  "clock jumped back past an earlier boundary",
+			queue: []float64{500.5, 2.5},
+			at:    3.2,
+			want:  []string{"b"},
+			left:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			clock := newCycleClock(time.Now())
+			q := newEvalQueue(clock, func(code string) error {
+				sent = append(sent, code)
+				return nil
+			}, quantizeCycle, 0)
+			for i, cycle := range tt.queue {
+				clock.Observe(posc.Tick{Cycle: cycle, CPS: 1e-9}, time.Now())
+				q.Send(string(rune('a' + i)))
+			}
+			clock.Observe(posc.Tick{Cycle: tt.at, CPS: 1e-9}, time.Now())
+			if err := q.release(); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(sent, tt.want) {
+				t.Errorf("sent %q, want %q", sent, tt.want)
+			}
+			if q.Pending() != tt.left {
+				t.Errorf("%d pending, want %d", q.Pending(), tt.left)
+			}
+			if q.Pending() > 0 && q.next().cycle > q.boundary(tt.at) {
+				t.Errorf("next release at cycle %v, after the next boundary %v", q.next().cycle, q.boundary(tt.at))
+			}
+		})
+	}
+}
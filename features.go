@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+// maxAnalysisSeconds limits how much of a long sample is decoded for analysis
+const maxAnalysisSeconds = 10
+
+// sampleFeatures are lightweight audio descriptors used to compare samples
+type sampleFeatures struct {
+	Duration float64 `json:"duration"` // seconds
+	Loudness float64 `json:"loudness"` // RMS in dBFS
+	Peak     float64 `json:"peak"`     // peak in dBFS
+	Onset    float64 `json:"onset"`    // seconds of leading silence
+	Attack   float64 `json:"attack"`   // seconds from onset to near peak
+	Centroid float64 `json:"centroid"` // energy weighted spectral centroid in Hz
+}
+
+// decodeMono reads up to maxAnalysisSeconds of a sound file as mono samples
+func decodeMono(path string) (samples []float64, rate int, duration float64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return decodeWavMono(f)
+	case ".aif", ".aiff", ".aifc":
+		return decodeAiffMono(bufio.NewReader(f))
+	}
+	return nil, 0, 0, fmt.Errorf("unsupported format: %s", filepath.Ext(path))
+}
+
+func decodeWavMono(r io.Reader) ([]float64, int, float64, error) {
+	s, format, err := wav.Decode(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer s.Close()
+
+	rate := int(format.SampleRate)
+	duration := float64(s.Len()) / float64(rate)
+	limit := rate * maxAnalysisSeconds
+
+	samples := make([]float64, 0, min(s.Len(), limit))
+	buf := make([][2]float64, 4096)
+	for len(samples) < limit {
+		n, ok := s.Stream(buf)
+		for _, frame := range buf[:n] {
+			samples = append(samples, (frame[0]+frame[1])/2)
+		}
+		if !ok {
+			break
+		}
+	}
+	return samples, rate, duration, s.Err()
+}
+
+// ieeeExtended decodes the 80 bit float AIFF uses for its sample rate
+func ieeeExtended(b [10]byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]) & 0x7fff)
+	mant := binary.BigEndian.Uint64(b[2:10])
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+	v := float64(mant) * math.Pow(2, float64(exp-16383-63))
+	if b[0]&0x80 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// decodeAiffMono decodes uncompressed big endian PCM AIFF and AIFC files
+func decodeAiffMono(r io.Reader) ([]float64, int, float64, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, 0, err
+	}
+	if string(header[0:4]) != "FORM" {
+		return nil, 0, 0, errors.New("aiff: missing FORM")
+	}
+	aifc := string(header[8:12]) == "AIFC"
+
+	var channels, bits int
+	var frames uint32
+	var rate float64
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, 0, 0, errors.New("aiff: missing sound data")
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.BigEndian.Uint32(chunk[4:8]))
+		// chunks are padded to an even size
+		padded := size + size%2
+
+		switch id {
+		case "COMM":
+			comm := make([]byte, padded)
+			if _, err := io.ReadFull(r, comm); err != nil {
+				return nil, 0, 0, err
+			}
+			channels = int(binary.BigEndian.Uint16(comm[0:2]))
+			frames = binary.BigEndian.Uint32(comm[2:6])
+			bits = int(binary.BigEndian.Uint16(comm[6:8]))
+			var ext [10]byte
+			copy(ext[:], comm[8:18])
+			rate = ieeeExtended(ext)
+			if aifc && len(comm) >= 22 && string(comm[18:22]) != "NONE" && string(comm[18:22]) != "twos" {
+				return nil, 0, 0, fmt.Errorf("aiff: unsupported compression %s", comm[18:22])
+			}
+		case "SSND":
+			if channels == 0 || rate == 0 {
+				return nil, 0, 0, errors.New("aiff: sound data before COMM")
+			}
+			var offset [8]byte
+			if _, err := io.ReadFull(r, offset[:]); err != nil {
+				return nil, 0, 0, err
+			}
+			if _, err := io.CopyN(io.Discard, r, int64(binary.BigEndian.Uint32(offset[0:4]))); err != nil {
+				return nil, 0, 0, err
+			}
+			return readPCMBigEndian(r, channels, bits, frames, int(rate))
+		default:
+			if _, err := io.CopyN(io.Discard, r, padded); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+	}
+}
+
+func readPCMBigEndian(r io.Reader, channels, bits int, frames uint32, rate int) ([]float64, int, float64, error) {
+	width := (bits + 7) / 8
+	if width < 1 || width > 4 {
+		return nil, 0, 0, fmt.Errorf("aiff: unsupported sample size %d", bits)
+	}
+	duration := float64(frames) / float64(rate)
+	n := min(int(frames), rate*maxAnalysisSeconds)
+	scale := math.Pow(2, float64(width*8-1))
+
+	samples := make([]float64, 0, n)
+	frame := make([]byte, width*channels)
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(r, frame); err != nil {
+			break
+		}
+		var sum float64
+		for c := 0; c < channels; c++ {
+			b := frame[c*width : (c+1)*width]
+			var v int32
+			for _, x := range b {
+				v = v<<8 | int32(x)
+			}
+			// sign extend
+			v <<= 32 - 8*width
+			v >>= 32 - 8*width
+			sum += float64(v) / scale
+		}
+		samples = append(samples, sum/float64(channels))
+	}
+	return samples, rate, duration, nil
+}
+
+func toDB(v float64) float64 {
+	if v <= 1e-9 {
+		return -180
+	}
+	return 20 * math.Log10(v)
+}
+
+// analyzeSample decodes a sample and computes its features
+func analyzeSample(path string) (sampleFeatures, error) {
+	samples, rate, duration, err := decodeMono(path)
+	if err != nil {
+		return sampleFeatures{}, err
+	}
+	if len(samples) == 0 || rate == 0 {
+		return sampleFeatures{Duration: duration, Loudness: -180, Peak: -180}, nil
+	}
+
+	var sum, peak float64
+	for _, v := range samples {
+		sum += v * v
+		peak = math.Max(peak, math.Abs(v))
+	}
+
+	onset, attack := envelopeTimes(samples, rate, peak)
+	return sampleFeatures{
+		Duration: duration,
+		Loudness: toDB(math.Sqrt(sum / float64(len(samples)))),
+		Peak:     toDB(peak),
+		Onset:    onset,
+		Attack:   attack,
+		Centroid: spectralCentroid(samples, rate),
+	}, nil
+}
+
+// envelopeTimes finds when the 5ms peak envelope first crosses 10% (onset)
+// and how long it then takes to reach 90% of the peak (attack)
+func envelopeTimes(samples []float64, rate int, peak float64) (onset, attack float64) {
+	if peak == 0 {
+		return 0, 0
+	}
+	win := max(rate/200, 1)
+	start := -1
+	for i := 0; i < len(samples); i += win {
+		var env float64
+		for _, v := range samples[i:min(i+win, len(samples))] {
+			env = math.Max(env, math.Abs(v))
+		}
+		if start < 0 && env >= 0.1*peak {
+			start = i
+		}
+		if env >= 0.9*peak {
+			return float64(start) / float64(rate), float64(i-start) / float64(rate)
+		}
+	}
+	return float64(max(start, 0)) / float64(rate), 0
+}
+
+const fftSize = 2048
+
+// spectralCentroid averages the centroid of hann windowed frames, weighted by their magnitude
+func spectralCentroid(samples []float64, rate int) float64 {
+	if len(samples) < fftSize {
+		padded := make([]float64, fftSize)
+		copy(padded, samples)
+		samples = padded
+	}
+
+	window := make([]float64, fftSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(fftSize-1))
+	}
+
+	buf := make([]complex128, fftSize)
+	var weighted, total float64
+	for start := 0; start+fftSize <= len(samples); start += fftSize / 2 {
+		for i := range buf {
+			buf[i] = complex(samples[start+i]*window[i], 0)
+		}
+		fft(buf)
+
+		var num, den float64
+		for k := 1; k < fftSize/2; k++ {
+			mag := cmplx.Abs(buf[k])
+			num += float64(k) * float64(rate) / fftSize * mag
+			den += mag
+		}
+		weighted += num
+		total += den
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// fft is an in place radix-2 Cooley-Tukey transform; len(a) must be a power of two
+func fft(a []complex128) {
+	n := len(a)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			wn := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := a[start+k]
+				v := a[start+k+size/2] * wn
+				a[start+k] = u + v
+				a[start+k+size/2] = u - v
+				wn *= w
+			}
+		}
+	}
+}
+
+// Valid reports whether the sample could be analyzed
+func (f sampleFeatures) Valid() bool {
+	return f.Duration >= 0
+}
+
+// vector scales features so that each contributes comparably to distances
+func (f sampleFeatures) vector() [4]float64 {
+	return [4]float64{
+		math.Log2(f.Duration + 0.01),
+		f.Loudness / 6,
+		math.Log2(f.Centroid + 20),
+		math.Log2(f.Attack + 0.001),
+	}
+}
+
+func featureDistance(a, b sampleFeatures) float64 {
+	va, vb := a.vector(), b.vector()
+	var d float64
+	for i := range va {
+		d += (va[i] - vb[i]) * (va[i] - vb[i])
+	}
+	return math.Sqrt(d)
+}
+
+// sampleFeature names a feature samples can be sorted by
+type sampleFeature int
+
+const (
+	featureIndex sampleFeature = iota
+	featureDuration
+	featureLoudness
+	featureCentroid
+	featureAttack
+	numSampleFeatures
+)
+
+func (f sampleFeature) String() string {
+	return [...]string{"index", "duration", "loudness", "brightness", "attack"}[f]
+}
+
+// Value returns the feature used for sorting
+func (f sampleFeature) Value(s sampleFeatures) float64 {
+	switch f {
+	case featureDuration:
+		return s.Duration
+	case featureLoudness:
+		return s.Loudness
+	case featureCentroid:
+		return s.Centroid
+	case featureAttack:
+		return s.Attack
+	}
+	return 0
+}
+
+// Format renders the feature value for display
+func (f sampleFeature) Format(s sampleFeatures) string {
+	switch f {
+	case featureDuration:
+		return fmt.Sprintf("%.2fs", s.Duration)
+	case featureLoudness:
+		return fmt.Sprintf("%.1fdB", s.Loudness)
+	case featureCentroid:
+		return fmt.Sprintf("%.0fHz", s.Centroid)
+	case featureAttack:
+		return fmt.Sprintf("%.0fms", s.Attack*1000)
+	}
+	return ""
+}
+
+// featuresMsg carries features analyzed in the background
+type featuresMsg struct {
+	features map[string]sampleFeatures
+	pending  []string
+}
+
+// featureBatchSize is how many samples are analyzed before reporting progress
+const featureBatchSize = 50
+
+// analyzeFeatures analyzes the next batch of pending paths
+func analyzeFeatures(pending []string) tea.Cmd {
+	if len(pending) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		n := min(featureBatchSize, len(pending))
+		features := make(map[string]sampleFeatures, n)
+		for _, path := range pending[:n] {
+			f, err := analyzeSample(path)
+			if err != nil {
+				log.Printf("analyzing %s: %v", path, err)
+				// mark it as analyzed so it isn't queued again
+				f = sampleFeatures{Duration: -1}
+			}
+			features[path] = f
+		}
+		return featuresMsg{features: features, pending: pending[n:]}
+	}
+}
@@ -37,10 +37,13 @@ func (i fileItem) FilterValue() string {
 }
 
 type FileBrowser struct {
-	l        list.Model
-	active   bool
-	curDir   string
-	onSelect func(path string) tea.Cmd
+	l           list.Model
+	active      bool
+	curDir      string
+	onSelect    func(path string) tea.Cmd
+	onChangeDir func(dir string) tea.Cmd
+	// itemHeight is the number of lines each entry takes, with its spacing
+	itemHeight int
 }
 
 type fileBrowserKeyMap struct {
@@ -70,8 +73,8 @@ var defaultFileBrowserKeyMap = fileBrowserKeyMap{
 		key.WithHelp("backspace", "go back"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "esc"),
-		key.WithHelp("q/esc", "quit"),
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "quit"),
 	),
 	GoToRoot: key.NewBinding(
 		key.WithKeys("g", "/"),
@@ -87,22 +90,21 @@ func NewFileBrowser() *FileBrowser {
 	}
 
 	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(lipgloss.Color("#FFFF00")).Background(lipgloss.Color("#333333"))
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(lipgloss.Color("#FFFF00")).Background(lipgloss.Color("#333333"))
+	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Foreground(theme.Text)
+	delegate.Styles.NormalDesc = delegate.Styles.NormalDesc.Foreground(theme.Muted)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(theme.Selection).Background(theme.Surface).BorderForeground(theme.Selection)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(theme.Selection).Background(theme.Surface).BorderForeground(theme.Selection)
 
 	l := list.New([]list.Item{}, delegate, 0, 0)
 	l.Title = "File Browser"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
-	l.Styles.Title = lipgloss.NewStyle().
-		Background(lipgloss.Color("#333333")).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Bold(true).
-		Padding(0, 1)
+	l.Styles.Title = titleBarStyle
 
 	m := &FileBrowser{
-		l:      l,
-		curDir: curDir,
+		l:          l,
+		curDir:     curDir,
+		itemHeight: delegate.Height() + delegate.Spacing(),
 	}
 
 	return m
@@ -112,6 +114,10 @@ func (m *FileBrowser) SetSize(width, height int) {
 	m.l.SetSize(width, height)
 }
 
+func (m *FileBrowser) KeyHelp() []helpSection {
+	return []helpSection{scopeHelp("file_browser")}
+}
+
 func (m *FileBrowser) SetActive(active bool) {
 	m.active = active
 }
@@ -172,9 +178,73 @@ func (m *FileBrowser) loadFiles() tea.Cmd {
 
 func (m *FileBrowser) SetDirectory(path string) tea.Cmd {
 	m.curDir = path
+	if m.onChangeDir != nil {
+		return tea.Batch(m.loadFiles(), m.onChangeDir(path))
+	}
+	return m.loadFiles()
+}
+
+// SetOnChangeDir sets a callback run whenever the browsed directory changes
+func (m *FileBrowser) SetOnChangeDir(f func(dir string) tea.Cmd) {
+	m.onChangeDir = f
+}
+
+// Dir returns the directory being browsed
+func (m *FileBrowser) Dir() string {
+	return m.curDir
+}
+
+// Refresh reloads the entries of the current directory
+func (m *FileBrowser) Refresh() tea.Cmd {
 	return m.loadFiles()
 }
 
+// open enters the selected directory or opens the selected file
+func (m *FileBrowser) open() tea.Cmd {
+	selectedItem := m.l.SelectedItem()
+	if selectedItem == nil {
+		return nil
+	}
+
+	fileItem := selectedItem.(fileItem)
+	if fileItem.isDir {
+		return m.SetDirectory(fileItem.path)
+	} else if m.onSelect != nil {
+		return m.onSelect(fileItem.path)
+	}
+	return nil
+}
+
+// Click selects the clicked entry, double clicking opens it
+func (m *FileBrowser) Click(x, y int, double bool) tea.Cmd {
+	// skip our title and the list's
+	titleHeight := lipgloss.Height(m.l.Styles.TitleBar.Render(m.l.Styles.Title.Render(m.l.Title)))
+	y -= 1 + titleHeight
+	if y < 0 {
+		return nil
+	}
+	row := y / m.itemHeight
+	if row >= m.l.Paginator.ItemsOnPage(len(m.l.VisibleItems())) {
+		return nil
+	}
+	m.l.Select(m.l.Paginator.Page*m.l.Paginator.PerPage + row)
+	if double {
+		return m.open()
+	}
+	return nil
+}
+
+// Scroll moves the selection by wheel steps
+func (m *FileBrowser) Scroll(delta int) tea.Cmd {
+	for ; delta < 0; delta++ {
+		m.l.CursorUp()
+	}
+	for ; delta > 0; delta-- {
+		m.l.CursorDown()
+	}
+	return nil
+}
+
 func (m *FileBrowser) Init() tea.Cmd {
 	return m.loadFiles()
 }
@@ -188,27 +258,16 @@ func (m *FileBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.active = false
 			return m, nil
 		case key.Matches(msg, defaultFileBrowserKeyMap.Enter):
-			selectedItem := m.l.SelectedItem()
-			if selectedItem == nil {
-				return m, nil
-			}
-
-			fileItem := selectedItem.(fileItem)
-			if fileItem.isDir {
-				return m, m.SetDirectory(fileItem.path)
-			} else if m.onSelect != nil {
-				return m, m.onSelect(fileItem.path)
-			}
+			return m, m.open()
 		case key.Matches(msg, defaultFileBrowserKeyMap.Back):
 			// Go up one directory
 			if m.curDir != "/" {
-				m.SetDirectory(filepath.Dir(m.curDir))
+				return m, m.SetDirectory(filepath.Dir(m.curDir))
 			}
 			return m, nil
 		case key.Matches(msg, defaultFileBrowserKeyMap.GoToRoot):
 			// Go to root directory
-			m.SetDirectory("/")
-			return m, nil
+			return m, m.SetDirectory("/")
 		}
 
 		var cmd tea.Cmd
@@ -226,12 +285,8 @@ func (m *FileBrowser) View() string {
 		return ""
 	}
 
-	title := lipgloss.NewStyle().
-		Background(lipgloss.Color("#333333")).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Bold(true).
+	title := titleBarStyle.
 		Width(m.l.Width()).
-		Padding(0, 1).
 		Render(fmt.Sprintf("File Browser - %s", m.curDir))
 
 	return lipgloss.JoinVertical(
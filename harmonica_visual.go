@@ -189,22 +189,9 @@ func (h *HarmonicaVisual) handleOscMessage(message string) {
 	h.circles[uniqueID] = circle
 }
 
-// availableColors returns a slice of predefined colors
+// availableColors returns the theme's palette for the circles
 func availableColors() []lipgloss.Color {
-	return []lipgloss.Color{
-		lipgloss.Color("#FF0000"), // Red
-		lipgloss.Color("#00FF00"), // Green
-		lipgloss.Color("#0000FF"), // Blue
-		lipgloss.Color("#FFFF00"), // Yellow
-		lipgloss.Color("#FF00FF"), // Magenta
-		lipgloss.Color("#00FFFF"), // Cyan
-		lipgloss.Color("#FFA500"), // Orange
-		lipgloss.Color("#800080"), // Purple
-		lipgloss.Color("#008000"), // Dark Green
-		lipgloss.Color("#000080"), // Navy
-		lipgloss.Color("#800000"), // Maroon
-		lipgloss.Color("#FF69B4"), // Hot Pink
-	}
+	return theme.Palette
 }
 
 // View implements the tea.Model interface
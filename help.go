@@ -0,0 +1,193 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpColumnWidth is the width of each section of the help overlay
+const helpColumnWidth = 42
+
+type helpKeyMap struct {
+	Close     key.Binding
+	ToggleAll key.Binding
+}
+
+var defaultHelpKeyMap = helpKeyMap{
+	Close: key.NewBinding(
+		key.WithKeys("esc", "?", "q"),
+		key.WithHelp("esc/?/q", "close help"),
+	),
+	ToggleAll: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "toggle all bindings"),
+	),
+}
+
+// helpSection is a titled group of bindings in the help overlay
+type helpSection struct {
+	title    string
+	bindings []key.Binding
+}
+
+// helpProvider is implemented by components that list their bindings in the help overlay
+type helpProvider interface {
+	KeyHelp() []helpSection
+}
+
+// scopeHelp lists the enabled bindings of a key scope
+func scopeHelp(name string) helpSection {
+	s, ok := findKeyScope(name)
+	if !ok {
+		return helpSection{title: name}
+	}
+	section := helpSection{title: s.title}
+	for _, b := range s.bindings() {
+		if b.binding.Enabled() {
+			section.bindings = append(section.bindings, *b.binding)
+		}
+	}
+	return section
+}
+
+// keyHelpSections lists the enabled bindings of every key scope
+func keyHelpSections() []helpSection {
+	sections := make([]helpSection, 0, len(keyScopes))
+	for _, s := range keyScopes {
+		sections = append(sections, scopeHelp(s.name))
+	}
+	return sections
+}
+
+func (s helpSection) View(width int) string {
+	h := help.New()
+	h.Width = width
+	h.Styles.FullKey = helpKeyStyle
+	h.Styles.FullDesc = helpDescStyle
+	h.Styles.FullSeparator = helpDescStyle
+	return lipgloss.NewStyle().Width(width).Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			helpTitleStyle.Render(s.title),
+			h.FullHelpView([][]key.Binding{s.bindings}),
+		),
+	)
+}
+
+// HelpView is an overlay listing key bindings, generated from the keymaps
+// so it always shows the configured keys
+type HelpView struct {
+	active bool
+	vp     viewport.Model
+	w, h   int
+
+	// context holds the sections of the focused component and global keys
+	context []helpSection
+	all     bool
+	// maxHeight is the tallest the viewport gets, it shrinks to fit fewer bindings
+	maxHeight int
+}
+
+func NewHelpView() *HelpView {
+	return &HelpView{vp: viewport.New(0, 0)}
+}
+
+func (m *HelpView) SetSize(w, h int) {
+	m.w = w
+	m.h = h
+	fw, fh := dialogBoxStyle.GetFrameSize()
+	m.vp.Width = max(w-fw-4, helpColumnWidth)
+	m.maxHeight = max(h-fh-2, 1)
+	m.vp.Height = m.maxHeight
+}
+
+func (m *HelpView) Active() bool {
+	return m.active
+}
+
+// CapturingInput is true while open so keys scroll or close the overlay
+func (m *HelpView) CapturingInput() bool {
+	return m.active
+}
+
+// Open shows the bindings available in the current context
+func (m *HelpView) Open(context []helpSection) {
+	m.active = true
+	m.all = false
+	m.context = context
+	m.render(context)
+}
+
+// render lays out the sections in as many columns as fit
+func (m *HelpView) render(sections []helpSection) {
+	cols := max(m.vp.Width/(helpColumnWidth+2), 1)
+
+	var rows []string
+	for i := 0; i < len(sections); i += cols {
+		var row []string
+		for _, s := range sections[i:min(i+cols, len(sections))] {
+			row = append(row, lipgloss.NewStyle().PaddingRight(2).PaddingBottom(1).Render(s.View(helpColumnWidth)))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	m.vp.Height = min(lipgloss.Height(content), m.maxHeight)
+	m.vp.SetContent(content)
+	m.vp.GotoTop()
+}
+
+func (m *HelpView) Close() {
+	m.active = false
+}
+
+// Scroll moves the bindings up or down by wheel steps
+func (m *HelpView) Scroll(delta int) tea.Cmd {
+	if delta < 0 {
+		m.vp.LineUp(-delta * wheelLines)
+		return nil
+	}
+	m.vp.LineDown(delta * wheelLines)
+	return nil
+}
+
+func (m *HelpView) Init() tea.Cmd {
+	return nil
+}
+
+func (m *HelpView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, defaultHelpKeyMap.Close):
+			m.active = false
+			return m, nil
+		case key.Matches(msg, defaultHelpKeyMap.ToggleAll):
+			m.all = !m.all
+			if m.all {
+				m.render(keyHelpSections())
+			} else {
+				m.render(m.context)
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.vp, cmd = m.vp.Update(msg)
+	return m, cmd
+}
+
+func (m *HelpView) View() string {
+	if !m.active {
+		return ""
+	}
+	h := help.New()
+	h.Styles.ShortKey = completionStyle
+	h.Styles.ShortDesc = completionStyle
+	footer := h.ShortHelpView([]key.Binding{defaultHelpKeyMap.Close, defaultHelpKeyMap.ToggleAll})
+	content := lipgloss.JoinVertical(lipgloss.Left, m.vp.View(), footer)
+	return dialogBoxStyle.Render(lipgloss.NewStyle().Padding(0, 1).Render(content))
+}
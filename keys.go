@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyScope is a keymap that can be configured under "keys" in the config,
+// e.g. {"keys": {"global": {"quit": ["ctrl+c"]}, "editor": {"hush": ["ctrl+x"]}}}
+type keyScope struct {
+	name   string
+	title  string
+	keymap any // pointer to a struct of key.Binding fields
+	// captures is set for scopes that get keys before the global keymap,
+	// as they are only active while taking text input
+	captures bool
+}
+
+var keyScopes = []keyScope{
+	{name: "global", title: "Global", keymap: &defaultKeyMap},
+	{name: "editor", title: "Editor (normal mode)", keymap: &defaultEditorKeyMap},
+	{name: "snippet", title: "Snippet placeholders (insert mode)", keymap: &defaultSnippetKeyMap, captures: true},
+	{name: "file_browser", title: "File browser", keymap: &defaultFileBrowserKeyMap},
+	{name: "sample_browser", title: "Sample browser", keymap: &defaultAudioBrowserKeyMap},
+	{name: "sample_finder", title: "Sample finder", keymap: &defaultSampleFinderKeyMap, captures: true},
+	{name: "console", title: "Console", keymap: &defaultConsoleKeyMap},
+	{name: "controls", title: "Controls", keymap: &defaultControlsKeyMap},
+	{name: "set_list", title: "Set list", keymap: &defaultSetListKeyMap},
+	{name: "palette", title: "Command palette", keymap: &defaultQuickSelectKeyMap, captures: true},
+	{name: "help", title: "Help", keymap: &defaultHelpKeyMap, captures: true},
+}
+
+// namedBinding is a binding with the action name used in the config
+type namedBinding struct {
+	action  string
+	binding *key.Binding
+}
+
+// actionName converts a keymap field name to its config name, e.g. ToggleVisuals to toggle_visuals
+func actionName(field string) string {
+	var sb strings.Builder
+	for i, r := range field {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// bindings returns the bindings of the scope's keymap in field order
+func (s keyScope) bindings() []namedBinding {
+	v := reflect.ValueOf(s.keymap).Elem()
+	var bindings []namedBinding
+	for i := 0; i < v.NumField(); i++ {
+		b, ok := v.Field(i).Addr().Interface().(*key.Binding)
+		if !ok {
+			continue
+		}
+		bindings = append(bindings, namedBinding{action: actionName(v.Type().Field(i).Name), binding: b})
+	}
+	return bindings
+}
+
+// applyKeyBindings replaces the keys of configured actions. An empty list
+// unbinds the action. Unknown scopes and actions are reported.
+func applyKeyBindings(overrides map[string]map[string][]string) error {
+	var errs []error
+	for scopeName, actions := range overrides {
+		scope, ok := findKeyScope(scopeName)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key scope %q", scopeName))
+			continue
+		}
+		bindings := scope.bindings()
+		for action, keys := range actions {
+			b, ok := findBinding(bindings, action)
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown action %q in key scope %q", action, scopeName))
+				continue
+			}
+			desc := b.Help().Desc
+			*b = key.NewBinding(
+				key.WithKeys(keys...),
+				key.WithHelp(strings.Join(keys, "/"), desc),
+			)
+			if len(keys) == 0 {
+				b.SetEnabled(false)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func findKeyScope(name string) (keyScope, bool) {
+	for _, s := range keyScopes {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return keyScope{}, false
+}
+
+func findBinding(bindings []namedBinding, action string) (*key.Binding, bool) {
+	for _, b := range bindings {
+		if b.action == action {
+			return b.binding, true
+		}
+	}
+	return nil, false
+}
+
+// keyConflict is a key bound to more than one action that can receive it
+type keyConflict struct {
+	key     string
+	actions []string
+}
+
+func (c keyConflict) String() string {
+	return fmt.Sprintf("%s is bound to %s", c.key, strings.Join(c.actions, ", "))
+}
+
+// keyAliases are component actions that do the same as a global action, so
+// sharing its keys isn't a conflict
+var keyAliases = map[string]string{
+	"file_browser.quit":   "global.focus_editor",
+	"sample_browser.quit": "global.toggle_audio_browser",
+}
+
+// findKeyConflicts reports keys bound twice within a scope, and keys bound
+// both globally and in a component scope. App.Update matches global keys
+// before passing them to a component that isn't taking text input, so the
+// global binding shadows the component's.
+func findKeyConflicts() []keyConflict {
+	var conflicts []keyConflict
+	check := func(scopes ...keyScope) {
+		seen := make(map[string][]string)
+		var order []string
+		for _, s := range scopes {
+			for _, b := range s.bindings() {
+				if !b.binding.Enabled() {
+					continue
+				}
+				for _, k := range b.binding.Keys() {
+					if _, ok := seen[k]; !ok {
+						order = append(order, k)
+					}
+					seen[k] = append(seen[k], s.name+"."+b.action)
+				}
+			}
+		}
+		for _, k := range order {
+			if len(seen[k]) > 1 {
+				conflicts = append(conflicts, keyConflict{key: k, actions: seen[k]})
+			}
+		}
+	}
+
+	global, _ := findKeyScope("global")
+	globalKeys := make(map[string][]string)
+	for _, b := range global.bindings() {
+		if !b.binding.Enabled() {
+			continue
+		}
+		for _, k := range b.binding.Keys() {
+			globalKeys[k] = append(globalKeys[k], global.name+"."+b.action)
+		}
+	}
+	for _, s := range keyScopes {
+		check(s)
+		if s.name == "global" || s.captures {
+			continue
+		}
+		for _, b := range s.bindings() {
+			if !b.binding.Enabled() {
+				continue
+			}
+			action := s.name + "." + b.action
+			for _, k := range b.binding.Keys() {
+				actions, ok := globalKeys[k]
+				if !ok || (len(actions) == 1 && keyAliases[action] == actions[0]) {
+					continue
+				}
+				conflicts = append(conflicts, keyConflict{
+					key:     k,
+					actions: append(append([]string{}, actions...), action),
+				})
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+func TestDefaultKeysHaveNoConflicts(t *testing.T) {
+	for _, c := range findKeyConflicts() {
+		t.Error(c)
+	}
+}
+
+func TestFindKeyConflictsGlobalShadowsComponent(t *testing.T) {
+	saved := defaultSetListKeyMap.Up
+	defer func() { defaultSetListKeyMap.Up = saved }()
+
+	defaultSetListKeyMap.Up = key.NewBinding(key.WithKeys("ctrl+p"))
+	want := keyConflict{key: "ctrl+p", actions: []string{"global.toggle_visuals", "set_list.up"}}
+	conflicts := findKeyConflicts()
+	if !slices.ContainsFunc(conflicts, func(c keyConflict) bool { return c.String() == want.String() }) {
+		t.Errorf("conflicts %v don't include %v", conflicts, want)
+	}
+}
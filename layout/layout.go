@@ -0,0 +1,429 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Split is the direction a node divides its space in
+type Split string
+
+const (
+	// Horizontal places children side by side
+	Horizontal Split = "horizontal"
+	// Vertical stacks children on top of each other
+	Vertical Split = "vertical"
+)
+
+// minRatio keeps resized panes from collapsing entirely
+const minRatio = 0.05
+
+// Node is either a pane or a split of child nodes
+type Node struct {
+	Pane     string  `json:"pane,omitempty"`
+	Split    Split   `json:"split,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+	// Ratio is the node's share of its parent relative to its siblings, 1 when unset
+	Ratio float64 `json:"ratio,omitempty"`
+	// Min is the node's minimum size along its parent's split, in cells
+	Min int `json:"min,omitempty"`
+	// Hidden panes start hidden when the layout is applied
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// Rect is the area of a pane on screen
+type Rect struct {
+	X, Y, W, H int
+}
+
+// Contains reports whether the cell at x, y is inside r
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+func (n *Node) ratio() float64 {
+	if n.Ratio <= 0 {
+		return 1
+	}
+	return n.Ratio
+}
+
+// Validate checks that every node is either a pane or a split with children
+func (n *Node) Validate() error {
+	if n == nil {
+		return errors.New("empty layout")
+	}
+	if n.Pane != "" {
+		if len(n.Children) > 0 {
+			return fmt.Errorf("pane %q can't have children", n.Pane)
+		}
+		return nil
+	}
+	if n.Split != Horizontal && n.Split != Vertical {
+		return fmt.Errorf("split must be %q or %q, got %q", Horizontal, Vertical, n.Split)
+	}
+	if len(n.Children) == 0 {
+		return fmt.Errorf("%s split has no children", n.Split)
+	}
+	for _, c := range n.Children {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clone deep copies the node so resizing doesn't change the original
+func (n *Node) Clone() *Node {
+	c := *n
+	c.Children = make([]*Node, len(n.Children))
+	for i, child := range n.Children {
+		c.Children[i] = child.Clone()
+	}
+	return &c
+}
+
+// Panes returns every pane in the tree, in order
+func (n *Node) Panes() []string {
+	if n.Pane != "" {
+		return []string{n.Pane}
+	}
+	var panes []string
+	for _, c := range n.Children {
+		panes = append(panes, c.Panes()...)
+	}
+	return panes
+}
+
+// Find returns the leaf of a pane
+func (n *Node) Find(pane string) (*Node, bool) {
+	if n.Pane == pane {
+		return n, true
+	}
+	for _, c := range n.Children {
+		if found, ok := c.Find(pane); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+func (n *Node) visible(isVisible func(pane string) bool) bool {
+	if n.Pane != "" {
+		return isVisible(n.Pane)
+	}
+	for _, c := range n.Children {
+		if c.visible(isVisible) {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout computes the area of every visible pane. Hidden panes give their
+// space to their siblings.
+func (n *Node) Layout(w, h int, isVisible func(pane string) bool) map[string]Rect {
+	rects := make(map[string]Rect)
+	n.layout(Rect{W: w, H: h}, isVisible, rects)
+	return rects
+}
+
+func (n *Node) layout(r Rect, isVisible func(pane string) bool, rects map[string]Rect) {
+	if n.Pane != "" {
+		if isVisible(n.Pane) {
+			rects[n.Pane] = r
+		}
+		return
+	}
+	children, childRects := n.split(r, isVisible)
+	for i, c := range children {
+		c.layout(childRects[i], isVisible, rects)
+	}
+}
+
+// split divides r between the visible children of a split node
+func (n *Node) split(r Rect, isVisible func(pane string) bool) ([]*Node, []Rect) {
+	var children []*Node
+	for _, c := range n.Children {
+		if c.visible(isVisible) {
+			children = append(children, c)
+		}
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+
+	total := r.H
+	if n.Split == Horizontal {
+		total = r.W
+	}
+	ratios := make([]float64, len(children))
+	mins := make([]int, len(children))
+	for i, c := range children {
+		ratios[i] = c.ratio()
+		mins[i] = c.Min
+	}
+
+	rects := make([]Rect, len(children))
+	offset := 0
+	for i, size := range distribute(total, ratios, mins) {
+		rects[i] = Rect{X: r.X, Y: r.Y + offset, W: r.W, H: size}
+		if n.Split == Horizontal {
+			rects[i] = Rect{X: r.X + offset, Y: r.Y, W: size, H: r.H}
+		}
+		offset += size
+	}
+	return children, rects
+}
+
+// distribute splits total by ratio, giving children at least their minimum
+// size when there's room. The last flexible child absorbs rounding.
+func distribute(total int, ratios []float64, mins []int) []int {
+	n := len(ratios)
+	pinned := make([]bool, n)
+	for {
+		remaining := total
+		var sum float64
+		for i := range ratios {
+			if pinned[i] {
+				remaining -= mins[i]
+			} else {
+				sum += ratios[i]
+			}
+		}
+		changed := false
+		for i := range ratios {
+			if !pinned[i] && sum > 0 && float64(remaining)*ratios[i]/sum < float64(mins[i]) {
+				pinned[i] = true
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	sizes := make([]int, n)
+	remaining := total
+	var sum float64
+	last := -1
+	for i := range ratios {
+		if pinned[i] {
+			sizes[i] = mins[i]
+			remaining -= mins[i]
+			continue
+		}
+		sum += ratios[i]
+		last = i
+	}
+	flexible := max(remaining, 0)
+	for i := range ratios {
+		if pinned[i] {
+			continue
+		}
+		if i == last {
+			sizes[i] = remaining
+			break
+		}
+		sizes[i] = int(float64(flexible) * ratios[i] / sum)
+		remaining -= sizes[i]
+	}
+
+	// every child is pinned and the mins don't fit, shrink from the end
+	over := -remaining
+	if last < 0 {
+		for i := n - 1; i >= 0 && over > 0; i-- {
+			cut := min(over, sizes[i])
+			sizes[i] -= cut
+			over -= cut
+		}
+	}
+	for i := range sizes {
+		sizes[i] = max(sizes[i], 0)
+	}
+	return sizes
+}
+
+// path returns the nodes from n down to the pane's leaf
+func (n *Node) path(pane string) []*Node {
+	if n.Pane == pane {
+		return []*Node{n}
+	}
+	for _, c := range n.Children {
+		if p := c.path(pane); p != nil {
+			return append([]*Node{n}, p...)
+		}
+	}
+	return nil
+}
+
+// Resize grows the pane's share of the nearest split in the given direction
+// by delta, a fraction of the split, shrinking its siblings. It reports
+// whether the pane is inside such a split.
+func (n *Node) Resize(pane string, split Split, delta float64) bool {
+	p := n.path(pane)
+	for i := len(p) - 2; i >= 0; i-- {
+		parent, child := p[i], p[i+1]
+		if parent.Split != split || len(parent.Children) < 2 {
+			continue
+		}
+		parent.resizeChild(child, delta)
+		return true
+	}
+	return false
+}
+
+// resizeChild normalizes the children's ratios and moves delta to or from child
+func (n *Node) resizeChild(child *Node, delta float64) {
+	var sum float64
+	for _, c := range n.Children {
+		sum += c.ratio()
+	}
+	share := child.ratio() / sum
+	next := min(max(share+delta, minRatio), 1-minRatio)
+
+	rest := 1 - share
+	for _, c := range n.Children {
+		if c == child {
+			c.Ratio = next
+			continue
+		}
+		if rest <= 0 {
+			c.Ratio = (1 - next) / float64(len(n.Children)-1)
+			continue
+		}
+		c.Ratio = c.ratio() / sum / rest * (1 - next)
+	}
+}
+
+// Divider is the edge between two visible children of a split, which can
+// be dragged to resize them
+type Divider struct {
+	parent   *Node
+	children []*Node
+	rects    []Rect
+	// index is the child before the divider
+	index int
+}
+
+// DividerAt returns the divider at x, y. The first row or column of a child
+// is its edge with the previous child. Dividers of nested splits win.
+func (n *Node) DividerAt(w, h int, isVisible func(pane string) bool, x, y int) (*Divider, bool) {
+	return n.dividerAt(Rect{W: w, H: h}, isVisible, x, y)
+}
+
+func (n *Node) dividerAt(r Rect, isVisible func(pane string) bool, x, y int) (*Divider, bool) {
+	if n.Pane != "" {
+		return nil, false
+	}
+	children, rects := n.split(r, isVisible)
+	for i, cr := range rects {
+		if !cr.Contains(x, y) {
+			continue
+		}
+		if d, ok := children[i].dividerAt(cr, isVisible, x, y); ok {
+			return d, true
+		}
+		if i == 0 {
+			return nil, false
+		}
+		if (n.Split == Horizontal && x == cr.X) || (n.Split == Vertical && y == cr.Y) {
+			return &Divider{parent: n, children: children, rects: rects, index: i - 1}, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// MoveTo moves the divider to x or y, depending on the direction of its
+// split, resizing the children on either side of it
+func (d *Divider) MoveTo(x, y int) {
+	before, after := &d.rects[d.index], &d.rects[d.index+1]
+	if d.parent.Split == Horizontal {
+		pos := min(max(x, before.X+1), after.X+after.W-1)
+		after.W += after.X - pos
+		before.W = pos - before.X
+		after.X = pos
+	} else {
+		pos := min(max(y, before.Y+1), after.Y+after.H-1)
+		after.H += after.Y - pos
+		before.H = pos - before.Y
+		after.Y = pos
+	}
+
+	// keep the visible children's share of the ratios so hidden siblings
+	// take the same space when shown again
+	var ratios float64
+	var total int
+	for i, c := range d.children {
+		ratios += c.ratio()
+		total += d.size(i)
+	}
+	if total <= 0 {
+		return
+	}
+	for i, c := range d.children {
+		c.Ratio = max(float64(d.size(i))/float64(total)*ratios, minRatio)
+	}
+}
+
+func (d *Divider) size(i int) int {
+	if d.parent.Split == Horizontal {
+		return d.rects[i].W
+	}
+	return d.rects[i].H
+}
+
+// Canvas returns h blank lines of width w to draw panes on
+func Canvas(w, h int) string {
+	line := strings.Repeat(" ", max(w, 0))
+	lines := make([]string, max(h, 0))
+	for i := range lines {
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Overlay draws fg over bg with its top left corner at x, y. Lines of fg
+// replace as many cells of bg as they are wide.
+func Overlay(bg, fg string, x, y int) string {
+	bgLines := strings.Split(bg, "\n")
+	for i, line := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(bgLines) {
+			continue
+		}
+		under := bgLines[row]
+		w := ansi.StringWidth(line)
+		if pad := x - ansi.StringWidth(under); pad > 0 {
+			under += strings.Repeat(" ", pad)
+		}
+		bgLines[row] = ansi.Truncate(under, x, "") + "\x1b[0m" + line + "\x1b[0m" + ansi.TruncateLeft(under, x+w, "")
+	}
+	return strings.Join(bgLines, "\n")
+}
+
+// Center draws fg over the middle of a w by h bg
+func Center(bg, fg string, w, h int) string {
+	r := Centered(fg, w, h)
+	return Overlay(bg, fg, r.X, r.Y)
+}
+
+// Centered returns the area fg covers when centered on a w by h screen
+func Centered(fg string, w, h int) Rect {
+	fw, fh := ansi.StringWidth(widest(fg)), strings.Count(fg, "\n")+1
+	return Rect{X: max((w-fw)/2, 0), Y: max((h-fh)/2, 0), W: fw, H: fh}
+}
+
+func widest(s string) string {
+	var widest string
+	for _, line := range strings.Split(s, "\n") {
+		if ansi.StringWidth(line) > ansi.StringWidth(widest) {
+			widest = line
+		}
+	}
+	return widest
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/treethought/perigee/layout"
+)
+
+// panes that can be placed in a layout
+const (
+	paneEditor   = "editor"
+	paneConsole  = "console"
+	paneVisuals  = "visuals"
+	paneSamples  = "samples"
+	paneFiles    = "files"
+	paneControls = "controls"
+	paneSetList  = "setlist"
+)
+
+var knownPanes = map[string]struct{}{
+	paneEditor: {}, paneConsole: {}, paneVisuals: {}, paneSamples: {}, paneFiles: {},
+	paneControls: {}, paneSetList: {},
+}
+
+// resizeStep is how much of a split a resize key moves
+const resizeStep = 0.05
+
+// defaultLayouts are the bundled layout presets. Layouts in the config
+// replace presets of the same name.
+var defaultLayouts = map[string]*layout.Node{
+	// default shows the editor over the tidal console, with the file browser,
+	// visuals and sample browser beside it when toggled
+	"default": {
+		Split: layout.Vertical,
+		Children: []*layout.Node{
+			{
+				Split: layout.Horizontal,
+				Ratio: 3,
+				Children: []*layout.Node{
+					{Pane: paneFiles, Min: 24, Hidden: true},
+					{Pane: paneEditor, Ratio: 2, Min: 20},
+					{Pane: paneVisuals, Min: 10, Hidden: true},
+					{Pane: paneSamples, Min: 16, Hidden: true},
+					{Pane: paneControls, Min: 24, Hidden: true},
+					{Pane: paneSetList, Min: 24, Hidden: true},
+				},
+			},
+			{Pane: paneConsole, Min: 10},
+		},
+	},
+	// perform gives most of the screen to the visuals for projecting,
+	// coding in a small pane below them
+	"perform": {
+		Split: layout.Vertical,
+		Children: []*layout.Node{
+			{Pane: paneVisuals, Ratio: 3},
+			{
+				Split: layout.Horizontal,
+				Min:   8,
+				Children: []*layout.Node{
+					{Pane: paneFiles, Hidden: true},
+					{Pane: paneEditor, Ratio: 2},
+					{Pane: paneSamples, Hidden: true},
+					{Pane: paneControls, Hidden: true},
+					{Pane: paneSetList, Hidden: true},
+					{Pane: paneConsole},
+				},
+			},
+		},
+	},
+	// rehearse keeps the sample browser and console open next to the editor
+	"rehearse": {
+		Split: layout.Horizontal,
+		Children: []*layout.Node{
+			{Pane: paneFiles, Min: 24, Hidden: true},
+			{
+				Split: layout.Vertical,
+				Ratio: 2,
+				Children: []*layout.Node{
+					{Pane: paneEditor, Ratio: 2, Min: 10},
+					{Pane: paneConsole, Min: 6},
+				},
+			},
+			{
+				Split: layout.Vertical,
+				Children: []*layout.Node{
+					{Pane: paneSamples, Ratio: 2, Min: 10},
+					{Pane: paneVisuals, Hidden: true},
+					{Pane: paneControls, Hidden: true},
+					{Pane: paneSetList, Hidden: true},
+				},
+			},
+		},
+	},
+}
+
+// layoutPresets merges the layouts from the config over the bundled ones
+func layoutPresets(configured map[string]*layout.Node) map[string]*layout.Node {
+	presets := make(map[string]*layout.Node, len(defaultLayouts)+len(configured))
+	for name, n := range defaultLayouts {
+		presets[name] = n
+	}
+	for name, n := range configured {
+		presets[name] = n
+	}
+	return presets
+}
+
+// validateLayouts checks the configured layouts and the selected preset
+func validateLayouts(cfg *Config) error {
+	var errs []error
+	for name, n := range cfg.Layouts {
+		if err := n.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("layout %q: %w", name, err))
+			continue
+		}
+		hasEditor := false
+		for _, pane := range n.Panes() {
+			if _, ok := knownPanes[pane]; !ok {
+				errs = append(errs, fmt.Errorf("layout %q: unknown pane %q", name, pane))
+			}
+			hasEditor = hasEditor || pane == paneEditor
+		}
+		if !hasEditor {
+			errs = append(errs, fmt.Errorf("layout %q: missing the editor pane", name))
+		}
+	}
+	if cfg.Layout != "" {
+		if _, ok := layoutPresets(cfg.Layouts)[cfg.Layout]; !ok {
+			errs = append(errs, fmt.Errorf("unknown layout %q", cfg.Layout))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// layoutNames returns the preset names in sorted order
+func (a *App) layoutNames() []string {
+	names := make([]string, 0, len(a.layouts))
+	for name := range a.layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyLayout switches to a preset, showing the panes it doesn't hide
+func (a *App) applyLayout(name string) tea.Cmd {
+	preset, ok := a.layouts[name]
+	if !ok {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("unknown layout %s", name))
+	}
+	a.layoutName = name
+	a.layout = preset.Clone()
+
+	show := func(pane string) bool {
+		n, ok := a.layout.Find(pane)
+		return ok && !n.Hidden
+	}
+
+	var cmds []tea.Cmd
+	a.consoleDeck.SetActive(show(paneConsole))
+	if show(paneVisuals) != a.visuals.Active() {
+		cmds = append(cmds, a.toggleVisuals())
+	}
+	a.sampleBrowser.SetActive(show(paneSamples))
+	a.fileBrowser.SetActive(show(paneFiles))
+	a.setControlsActive(show(paneControls))
+	a.setList.SetActive(show(paneSetList))
+
+	if !a.paneVisible(a.focusedPane()) {
+		a.active = a.editor
+	}
+	a.SetSize(a.w, a.h)
+	return tea.Batch(cmds...)
+}
+
+// switchLayout applies a preset and reports it in the status line
+func (a *App) switchLayout(name string) tea.Cmd {
+	return tea.Batch(a.applyLayout(name), a.editor.e.SetStatusMessage("layout: "+name))
+}
+
+// nextLayout cycles through the presets in name order
+func (a *App) nextLayout() tea.Cmd {
+	names := a.layoutNames()
+	for i, name := range names {
+		if name == a.layoutName {
+			return a.switchLayout(names[(i+1)%len(names)])
+		}
+	}
+	return a.switchLayout(names[0])
+}
+
+func (a *App) paneVisible(pane string) bool {
+	switch pane {
+	case paneEditor:
+		return true
+	case paneConsole:
+		return a.consoleDeck.Active()
+	case paneVisuals:
+		return a.visuals.Active()
+	case paneSamples:
+		return a.sampleBrowser.Active()
+	case paneFiles:
+		return a.fileBrowser.Active()
+	case paneControls:
+		return a.controls.Active()
+	case paneSetList:
+		return a.setList.Active()
+	}
+	return false
+}
+
+// focusedPane returns the pane of the focused component
+func (a *App) focusedPane() string {
+	switch a.active {
+	case a.sampleBrowser:
+		return paneSamples
+	case a.fileBrowser:
+		return paneFiles
+	case a.consoleDeck:
+		return paneConsole
+	case a.controls:
+		return paneControls
+	case a.setList:
+		return paneSetList
+	}
+	return paneEditor
+}
+
+// resizeFocused grows or shrinks the focused pane along a split direction
+func (a *App) resizeFocused(split layout.Split, delta float64) tea.Cmd {
+	pane := a.focusedPane()
+	if !a.layout.Resize(pane, split, delta) {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("can't resize %s %sly", pane, split))
+	}
+	a.SetSize(a.w, a.h)
+	return nil
+}
+
+// renderLayout draws every visible pane in its area of the screen
+func (a *App) renderLayout() string {
+	rects := a.layout.Layout(a.w, a.h, a.paneVisible)
+	canvas := layout.Canvas(a.w, a.h)
+	for _, pane := range a.layout.Panes() {
+		r, ok := rects[pane]
+		if !ok || r.W <= 0 || r.H <= 0 {
+			continue
+		}
+		view := lipgloss.NewStyle().MaxWidth(r.W).MaxHeight(r.H).Render(a.paneModel(pane).View())
+		canvas = layout.Overlay(canvas, view, r.X, r.Y)
+	}
+	return canvas
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -15,10 +16,48 @@ var cfg = &Config{
 }
 
 func main() {
+	cfgFile := filepath.Join(configDir(), "config.json")
+	if err := loadConfig(cfg, cfgFile); err != nil {
+		fmt.Printf("fatal: loading %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+	if err := applyKeyBindings(cfg.Keys); err != nil {
+		fmt.Printf("fatal: key bindings in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
+	t, err := loadTheme(cfg)
+	if err != nil {
+		fmt.Printf("fatal: theme in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+	applyTheme(t)
+
+	if err := validateControls(cfg.Controls); err != nil {
+		fmt.Printf("fatal: controls in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
+	if err := validateQuantize(cfg); err != nil {
+		fmt.Printf("fatal: quantize in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
+	if err := validateMIDI(cfg.MIDI); err != nil {
+		fmt.Printf("fatal: midi in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
+	if err := validateLayouts(cfg); err != nil {
+		fmt.Printf("fatal: layouts in %s: %v\n", cfgFile, err)
+		os.Exit(1)
+	}
+
 	a := NewApp(cfg)
 
 	defer a.repl.Stop()
 	defer a.sclang.Stop()
+	defer a.server.Close()
 
 	f, err := tea.LogToFile("debug.log", "debug")
 	if err != nil {
@@ -26,6 +65,11 @@ func main() {
 		os.Exit(1)
 	}
 	defer f.Close()
+	defer func() {
+		if err := a.sampleDB.Flush(); err != nil {
+			log.Println("Error saving sample db:", err)
+		}
+	}()
 
   log.Println("---------- perigee ----------")
 	p := tea.NewProgram(a,
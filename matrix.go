@@ -109,19 +109,18 @@ func (m *MatrixText) initCharacters() {
 		switch colorChoice {
 		case 0:
 			// Bright green for primary characters
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
+			style = lipgloss.NewStyle().Foreground(theme.Success).Bold(true)
 		case 1:
-			// Lighter green for secondary characters
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAFFAA"))
+			// Fainter green for secondary characters
+			style = lipgloss.NewStyle().Foreground(theme.Success).Faint(true)
 		case 2:
-			// pink
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF00FF"))
+			// Accent color for contrast
+			style = lipgloss.NewStyle().Foreground(theme.Accent)
 		case 3:
-			// blue
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
+			style = lipgloss.NewStyle().Foreground(theme.Info)
 		default:
-			// Dim green for background characters
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#005500"))
+			// Dim for background characters
+			style = lipgloss.NewStyle().Foreground(theme.Muted)
 		}
 
 		// Distribute across the width
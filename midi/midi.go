@@ -0,0 +1,194 @@
+// Package midi reads note and control change messages from raw MIDI
+// devices, like the ALSA rawmidi devices in /dev/snd. Ports of the ALSA
+// sequencer, including virtual ones, are available as rawmidi devices
+// through the snd-virmidi module.
+package midi
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Kind is the type of a MIDI message
+type Kind int
+
+const (
+	NoteOn Kind = iota
+	NoteOff
+	ControlChange
+)
+
+func (k Kind) String() string {
+	switch k {
+	case NoteOn:
+		return "note on"
+	case NoteOff:
+		return "note off"
+	}
+	return "cc"
+}
+
+// Message is a channel voice message
+type Message struct {
+	Kind Kind
+	// Channel is 1 to 16
+	Channel int
+	// Number is the note or controller number
+	Number int
+	// Value is the velocity or controller value, 0 to 127
+	Value int
+}
+
+func (m Message) String() string {
+	return fmt.Sprintf("%s %d ch%d = %d", m.Kind, m.Number, m.Channel, m.Value)
+}
+
+// Parser decodes a MIDI byte stream, handling running status and skipping
+// the messages it doesn't decode
+type Parser struct {
+	status byte
+	data   []byte
+	sysex  bool
+}
+
+// dataLen returns how many data bytes follow a status byte
+func dataLen(status byte) int {
+	switch status & 0xF0 {
+	case 0xC0, 0xD0:
+		return 1
+	case 0xF0:
+		switch status {
+		case 0xF1, 0xF3:
+			return 1
+		case 0xF2:
+			return 2
+		}
+		return 0
+	}
+	return 2
+}
+
+// Feed adds a byte and returns the message it completes, if any
+func (p *Parser) Feed(b byte) (Message, bool) {
+	switch {
+	case b >= 0xF8:
+		// real time messages can appear anywhere and don't affect the status
+		return Message{}, false
+	case b == 0xF0:
+		p.sysex = true
+		p.status = 0
+		return Message{}, false
+	case b == 0xF7:
+		p.sysex = false
+		return Message{}, false
+	case b&0x80 != 0:
+		p.sysex = false
+		p.status = b
+		if b >= 0xF0 && dataLen(b) == 0 {
+			p.status = 0
+		}
+		p.data = p.data[:0]
+		return Message{}, false
+	case p.sysex || p.status == 0:
+		return Message{}, false
+	}
+
+	p.data = append(p.data, b)
+	if len(p.data) < dataLen(p.status) {
+		return Message{}, false
+	}
+	data := p.data
+	p.data = p.data[:0]
+	if p.status >= 0xF0 {
+		// system common messages don't allow running status
+		p.status = 0
+		return Message{}, false
+	}
+
+	channel := int(p.status&0x0F) + 1
+	switch p.status & 0xF0 {
+	case 0x90:
+		if data[1] == 0 {
+			return Message{Kind: NoteOff, Channel: channel, Number: int(data[0])}, true
+		}
+		return Message{Kind: NoteOn, Channel: channel, Number: int(data[0]), Value: int(data[1])}, true
+	case 0x80:
+		return Message{Kind: NoteOff, Channel: channel, Number: int(data[0]), Value: int(data[1])}, true
+	case 0xB0:
+		return Message{Kind: ControlChange, Channel: channel, Number: int(data[0]), Value: int(data[1])}, true
+	}
+	return Message{}, false
+}
+
+// Devices lists the rawmidi devices, like /dev/snd/midiC1D0
+func Devices() ([]string, error) {
+	devices, err := filepath.Glob("/dev/snd/midiC*D*")
+	sort.Strings(devices)
+	return devices, err
+}
+
+// Input reads messages from a device
+type Input struct {
+	path string
+	r    io.ReadCloser
+	out  chan Message
+}
+
+// Open opens a rawmidi device. An empty path opens the first one found.
+func Open(path string) (*Input, error) {
+	if path == "" {
+		devices, err := Devices()
+		if err != nil {
+			return nil, err
+		}
+		if len(devices) == 0 {
+			return nil, fmt.Errorf("no MIDI devices in /dev/snd")
+		}
+		path = devices[0]
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Input{path: path, r: f, out: make(chan Message, 100)}, nil
+}
+
+func (in *Input) Path() string {
+	return in.path
+}
+
+// Start reads messages until the device is closed or unplugged
+func (in *Input) Start() {
+	var p Parser
+	buf := make([]byte, 64)
+	for {
+		n, err := in.r.Read(buf)
+		for _, b := range buf[:n] {
+			if msg, ok := p.Feed(b); ok {
+				select {
+				case in.out <- msg:
+				default:
+					log.Println("dropped midi message")
+				}
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("stopped reading %s: %v", in.path, err)
+			}
+			return
+		}
+	}
+}
+
+func (in *Input) Out() chan Message {
+	return in.out
+}
+
+func (in *Input) Close() error {
+	return in.r.Close()
+}
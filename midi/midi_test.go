@@ -0,0 +1,104 @@
+package midi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParserFeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+		want  []Message
+	}{
+		{
+			name:  "note on",
+			bytes: []byte{0x90, 60, 100},
+			want:  []Message{{Kind: NoteOn, Channel: 1, Number: 60, Value: 100}},
+		},
+		{
+			name:  "note off",
+			bytes: []byte{0x83, 60, 64},
+			want:  []Message{{Kind: NoteOff, Channel: 4, Number: 60, Value: 64}},
+		},
+		{
+			name:  "note on with velocity 0 is a note off",
+			bytes: []byte{0x9F, 60, 0},
+			want:  []Message{{Kind: NoteOff, Channel: 16, Number: 60}},
+		},
+		{
+			name:  "control change",
+			bytes: []byte{0xB1, 7, 127},
+			want:  []Message{{Kind: ControlChange, Channel: 2, Number: 7, Value: 127}},
+		},
+		{
+			name:  "running status",
+			bytes: []byte{0xB0, 1, 10, 1, 11, 2, 12},
+			want: []Message{
+				{Kind: ControlChange, Channel: 1, Number: 1, Value: 10},
+				{Kind: ControlChange, Channel: 1, Number: 1, Value: 11},
+				{Kind: ControlChange, Channel: 1, Number: 2, Value: 12},
+			},
+		},
+		{
+			name:  "real time bytes interleaved",
+			bytes: []byte{0xF8, 0x90, 0xF8, 60, 0xFA, 100, 0xFE, 62, 0xF8, 90},
+			want: []Message{
+				{Kind: NoteOn, Channel: 1, Number: 60, Value: 100},
+				{Kind: NoteOn, Channel: 1, Number: 62, Value: 90},
+			},
+		},
+		{
+			name:  "sysex skipped",
+			bytes: []byte{0xF0, 0x7E, 60, 100, 0xF7, 0x90, 61, 100},
+			want:  []Message{{Kind: NoteOn, Channel: 1, Number: 61, Value: 100}},
+		},
+		{
+			name:  "status byte ends unterminated sysex",
+			bytes: []byte{0xF0, 0x7E, 60, 100, 0x90, 61, 100},
+			want:  []Message{{Kind: NoteOn, Channel: 1, Number: 61, Value: 100}},
+		},
+		{
+			name:  "sysex ends running status",
+			bytes: []byte{0x90, 60, 100, 0xF0, 1, 2, 0xF7, 61, 100},
+			want:  []Message{{Kind: NoteOn, Channel: 1, Number: 60, Value: 100}},
+		},
+		{
+			name:  "system common skipped without running status",
+			bytes: []byte{0x90, 60, 100, 0xF2, 1, 2, 61, 100, 0xF3, 4, 0x90, 62, 100},
+			want: []Message{
+				{Kind: NoteOn, Channel: 1, Number: 60, Value: 100},
+				{Kind: NoteOn, Channel: 1, Number: 62, Value: 100},
+			},
+		},
+		{
+			name:  "program change and aftertouch skipped",
+			bytes: []byte{0xC0, 5, 5, 0xD0, 40, 0xA0, 60, 40, 0xB0, 1, 2},
+			want:  []Message{{Kind: ControlChange, Channel: 1, Number: 1, Value: 2}},
+		},
+		{
+			name:  "data before any status skipped",
+			bytes: []byte{60, 100, 0x90, 60, 100},
+			want:  []Message{{Kind: NoteOn, Channel: 1, Number: 60, Value: 100}},
+		},
+		{
+			name:  "new status drops an incomplete message",
+			bytes: []byte{0x90, 60, 0xB0, 7, 100},
+			want:  []Message{{Kind: ControlChange, Channel: 1, Number: 7, Value: 100}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parser
+			var got []Message
+			for _, b := range tt.bytes {
+				if msg, ok := p.Feed(b); ok {
+					got = append(got, msg)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/treethought/perigee/midi"
+)
+
+// MIDIConfig enables MIDI input and maps a controller's CCs and notes to
+// controls and actions
+type MIDIConfig struct {
+	// Device is a raw MIDI device like /dev/snd/midiC1D0, the first one
+	// found when unset
+	Device   string        `json:"device"`
+	Mappings []MIDIMapping `json:"mappings"`
+}
+
+// MIDIMapping runs an action when a CC or note is received
+type MIDIMapping struct {
+	// Type is "cc" or "note"
+	Type string `json:"type"`
+	// Channel is 1 to 16, any channel when unset
+	Channel int `json:"channel,omitempty"`
+	// Number is the controller or note number
+	Number int `json:"number"`
+	// Action is "ctrl", "mute", "solo", "hush", "eval" or "block"
+	Action string `json:"action"`
+	// Control is the control set by "ctrl", scaled from the CC value or
+	// note velocity to the control's range
+	Control string `json:"control,omitempty"`
+	// Slot is the pattern toggled by "mute" and "solo", e.g. 1 for d1
+	Slot int `json:"slot,omitempty"`
+	// Code is the code run by "eval"
+	Code string `json:"code,omitempty"`
+	// Block is the named block or scene evaluated by "block"
+	Block string `json:"block,omitempty"`
+}
+
+// matches reports whether a message triggers the mapping. Notes trigger
+// when pressed and CCs whenever they change.
+func (m MIDIMapping) matches(msg midi.Message) bool {
+	if m.Channel != 0 && m.Channel != msg.Channel {
+		return false
+	}
+	switch m.Type {
+	case "cc":
+		return msg.Kind == midi.ControlChange && msg.Number == m.Number
+	case "note":
+		return msg.Kind == midi.NoteOn && msg.Number == m.Number
+	}
+	return false
+}
+
+// sameTrigger reports whether two mappings respond to the same messages
+func (m MIDIMapping) sameTrigger(o MIDIMapping) bool {
+	return m.Type == o.Type && m.Channel == o.Channel && m.Number == o.Number
+}
+
+func (m MIDIMapping) trigger() string {
+	s := fmt.Sprintf("%s %d", m.Type, m.Number)
+	if m.Channel != 0 {
+		s += fmt.Sprintf(" ch%d", m.Channel)
+	}
+	return s
+}
+
+func (m MIDIMapping) action() string {
+	switch m.Action {
+	case "ctrl":
+		return "ctrl " + m.Control
+	case "mute", "solo":
+		return fmt.Sprintf("%s d%d", m.Action, m.Slot)
+	case "eval":
+		return "eval " + m.Code
+	case "block":
+		return "block @" + m.Block
+	}
+	return m.Action
+}
+
+func (m MIDIMapping) String() string {
+	return m.trigger() + " → " + m.action()
+}
+
+// validate checks the action of a mapping, and its trigger unless it is
+// being learned
+func (m MIDIMapping) validate(learning bool) error {
+	if !learning {
+		if m.Type != "cc" && m.Type != "note" {
+			return fmt.Errorf("unknown type %q, want cc or note", m.Type)
+		}
+		if m.Channel < 0 || m.Channel > 16 || m.Number < 0 || m.Number > 127 {
+			return fmt.Errorf("%s is out of range", m.trigger())
+		}
+	}
+	switch m.Action {
+	case "ctrl":
+		if m.Control == "" {
+			return errors.New("ctrl needs a control")
+		}
+	case "mute", "solo":
+		if m.Slot < 1 || m.Slot > 16 {
+			return fmt.Errorf("%s needs a slot from 1 to 16", m.Action)
+		}
+	case "eval":
+		if m.Code == "" {
+			return errors.New("eval needs code")
+		}
+	case "block":
+		if m.Block == "" {
+			return errors.New("block needs a block or scene name")
+		}
+	case "hush":
+	default:
+		return fmt.Errorf("unknown action %q, want ctrl, mute, solo, hush, eval or block", m.Action)
+	}
+	return nil
+}
+
+// validateMIDI checks the mappings in the config
+func validateMIDI(cfg *MIDIConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []error
+	for i, m := range cfg.Mappings {
+		if err := m.validate(false); err != nil {
+			errs = append(errs, fmt.Errorf("mapping %d: %w", i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseMIDIAction reads an action typed for MIDI learn, e.g. "hush",
+// "mute 1", "solo 2", "ctrl cutoff", "eval once $ s \"bd\"" or "block intro"
+func parseMIDIAction(s string) (MIDIMapping, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(s), " ")
+	arg = strings.TrimSpace(arg)
+	m := MIDIMapping{Action: name}
+	switch name {
+	case "ctrl":
+		m.Control = arg
+	case "mute", "solo":
+		m.Slot, _ = strconv.Atoi(strings.TrimPrefix(arg, "d"))
+	case "eval":
+		m.Code = arg
+	case "block":
+		m.Block = strings.TrimPrefix(arg, "@")
+	}
+	return m, m.validate(true)
+}
+
+type midiMsg midi.Message
+
+// midiLearnMsg starts learning the CC or note for a mapping
+type midiLearnMsg struct {
+	mapping MIDIMapping
+}
+
+// midiErrMsg reports a MIDI device that couldn't be opened
+type midiErrMsg struct {
+	err error
+}
+
+func listenMIDI(ch chan midi.Message) tea.Cmd {
+	return func() tea.Msg {
+		return midiMsg(<-ch)
+	}
+}
+
+// midiMapper runs the mapped actions of incoming MIDI messages
+type midiMapper struct {
+	cfg   *MIDIConfig
+	input *midi.Input
+
+	// learnedPath stores the mappings learned in perigee, which take
+	// precedence over those in the config
+	learnedPath string
+	learned     []MIDIMapping
+	// learning is the mapping waiting for a CC or note
+	learning *MIDIMapping
+
+	muted  map[int]bool
+	soloed map[int]bool
+}
+
+// newMIDIMapper loads the learned mappings. MIDI is disabled when cfg is nil.
+func newMIDIMapper(cfg *MIDIConfig, learnedPath string) *midiMapper {
+	m := &midiMapper{
+		cfg:         cfg,
+		learnedPath: learnedPath,
+		muted:       make(map[int]bool),
+		soloed:      make(map[int]bool),
+	}
+	data, err := os.ReadFile(learnedPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &m.learned)
+	}
+	if err != nil {
+		log.Println("failed to load midi mappings:", err)
+	}
+	return m
+}
+
+func (m *midiMapper) Enabled() bool {
+	return m.cfg != nil
+}
+
+// start opens the device in the background
+func (m *midiMapper) start() tea.Cmd {
+	if !m.Enabled() {
+		return nil
+	}
+	return func() tea.Msg {
+		in, err := midi.Open(expandPath(m.cfg.Device))
+		if err != nil {
+			return midiErrMsg{err: err}
+		}
+		log.Println("reading midi from", in.Path())
+		m.input = in
+		go in.Start()
+		return listenMIDI(in.Out())()
+	}
+}
+
+// mappings returns the mappings a message triggers
+func (m *midiMapper) mappings(msg midi.Message) []MIDIMapping {
+	var found []MIDIMapping
+	for _, mapping := range m.learned {
+		if mapping.matches(msg) {
+			found = append(found, mapping)
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+	for _, mapping := range m.cfg.Mappings {
+		if mapping.matches(msg) {
+			found = append(found, mapping)
+		}
+	}
+	return found
+}
+
+// learn maps the trigger of msg to the mapping being learned and saves the
+// learned mappings in the background. Note offs are ignored.
+func (m *midiMapper) learn(msg midi.Message) (MIDIMapping, tea.Cmd, bool) {
+	if m.learning == nil || msg.Kind == midi.NoteOff {
+		return MIDIMapping{}, nil, false
+	}
+	mapping := *m.learning
+	m.learning = nil
+	mapping.Type = "cc"
+	if msg.Kind == midi.NoteOn {
+		mapping.Type = "note"
+	}
+	mapping.Channel = msg.Channel
+	mapping.Number = msg.Number
+
+	learned := []MIDIMapping{mapping}
+	for _, l := range m.learned {
+		if !l.sameTrigger(mapping) {
+			learned = append(learned, l)
+		}
+	}
+	m.learned = learned
+
+	data, err := json.MarshalIndent(learned, "", "  ")
+	if err != nil {
+		return mapping, nil, true
+	}
+	path := m.learnedPath
+	return mapping, func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Println("failed to save midi mappings:", err)
+			return nil
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Println("failed to save midi mappings:", err)
+		}
+		return nil
+	}, true
+}
+
+// toggle flips the mute or solo state of a slot, returning the Tidal
+// code applying it
+func (m *midiMapper) toggle(action string, slot int) string {
+	state := m.muted
+	if action == "solo" {
+		state = m.soloed
+	}
+	state[slot] = !state[slot]
+	if state[slot] {
+		return fmt.Sprintf("%s %d", action, slot)
+	}
+	return fmt.Sprintf("un%s %d", action, slot)
+}
+
+// startMIDILearn waits for the next CC or note to map it to an action
+func (a *App) startMIDILearn(mapping MIDIMapping) tea.Cmd {
+	if !a.midi.Enabled() {
+		return a.editor.e.SetStatusMessage("MIDI is disabled, add \"midi\" to the config")
+	}
+	a.midi.learning = &mapping
+	return a.editor.e.SetStatusMessage(fmt.Sprintf("move a MIDI control to map it to %s (esc cancels)", mapping.action()))
+}
+
+// handleMIDI learns or runs the mappings of a message
+func (a *App) handleMIDI(msg midi.Message) tea.Cmd {
+	cmds := []tea.Cmd{listenMIDI(a.midi.input.Out())}
+	if mapping, save, ok := a.midi.learn(msg); ok {
+		cmds = append(cmds, save, a.editor.e.SetStatusMessage("mapped "+mapping.String()))
+		return tea.Batch(cmds...)
+	}
+	for _, mapping := range a.midi.mappings(msg) {
+		cmds = append(cmds, a.runMIDIAction(mapping, msg))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (a *App) runMIDIAction(mapping MIDIMapping, msg midi.Message) tea.Cmd {
+	if mapping.Action == "ctrl" {
+		return a.controls.SetNormalized(mapping.Control, float64(msg.Value)/127, a.editor.Controls())
+	}
+	// buttons send a CC of 0 when released
+	if msg.Kind == midi.ControlChange && msg.Value == 0 {
+		return nil
+	}
+	switch mapping.Action {
+	case "mute", "solo":
+		return a.sendTidal(a.midi.toggle(mapping.Action, mapping.Slot))
+	case "hush":
+		return a.queueTidal("hush")
+	case "eval":
+		return a.queueTidal(mapping.Code)
+	case "block":
+		return a.evalBlock(mapping.Block)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/treethought/perigee/layout"
+)
+
+// doubleClickInterval is the longest time between the clicks of a double click
+const doubleClickInterval = 400 * time.Millisecond
+
+// wheelLines is how far text scrolls for each step of the mouse wheel
+const wheelLines = 3
+
+// clickHandler is implemented by components that respond to clicks.
+// x and y are relative to the component's top left corner.
+type clickHandler interface {
+	Click(x, y int, double bool) tea.Cmd
+}
+
+// scrollHandler is implemented by components that scroll with the mouse
+// wheel. delta is the number of wheel steps, negative when scrolling up.
+type scrollHandler interface {
+	Scroll(delta int) tea.Cmd
+}
+
+// click is the last left click, used to detect double clicks
+type click struct {
+	x, y int
+	at   time.Time
+}
+
+func (a *App) doubleClick(msg tea.MouseMsg) bool {
+	now := time.Now()
+	double := a.lastClick.x == msg.X && a.lastClick.y == msg.Y && now.Sub(a.lastClick.at) < doubleClickInterval
+	a.lastClick = click{x: msg.X, y: msg.Y, at: now}
+	if double {
+		// a third click starts a new double click
+		a.lastClick = click{}
+	}
+	return double
+}
+
+func wheelDelta(msg tea.MouseMsg) int {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return -1
+	case tea.MouseButtonWheelDown:
+		return 1
+	}
+	return 0
+}
+
+// paneModel returns the component shown in a pane
+func (a *App) paneModel(pane string) tea.Model {
+	switch pane {
+	case paneConsole:
+		return a.consoleDeck
+	case paneVisuals:
+		return a.visuals
+	case paneSamples:
+		return a.sampleBrowser
+	case paneFiles:
+		return a.fileBrowser
+	case paneControls:
+		return a.controls
+	case paneSetList:
+		return a.setList
+	}
+	return a.editor
+}
+
+// paneAt returns the visible pane under x, y and its area
+func (a *App) paneAt(x, y int) (string, layout.Rect, bool) {
+	for pane, r := range a.layout.Layout(a.w, a.h, a.paneVisible) {
+		if r.Contains(x, y) {
+			return pane, r, true
+		}
+	}
+	return "", layout.Rect{}, false
+}
+
+// focusPane gives keyboard focus to the component of a pane. The visuals
+// don't take input, so clicking them keeps the current focus.
+func (a *App) focusPane(pane string) {
+	switch pane {
+	case paneEditor, paneConsole, paneSamples, paneFiles, paneControls, paneSetList:
+		a.active = a.paneModel(pane)
+	}
+}
+
+// handleMouse focuses and forwards clicks to the pane under the pointer,
+// scrolls it with the wheel, and resizes panes by dragging their edges
+func (a *App) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if a.help.Active() || a.qs.Active() {
+		return a.overlayMouse(msg)
+	}
+
+	switch msg.Action {
+	case tea.MouseActionRelease:
+		a.drag = nil
+		return nil
+	case tea.MouseActionMotion:
+		if a.drag != nil {
+			a.drag.MoveTo(msg.X, msg.Y)
+			a.SetSize(a.w, a.h)
+		}
+		return nil
+	}
+
+	pane, r, ok := a.paneAt(msg.X, msg.Y)
+	if !ok {
+		return nil
+	}
+	if tea.MouseEvent(msg).IsWheel() {
+		if s, ok := a.paneModel(pane).(scrollHandler); ok {
+			return s.Scroll(wheelDelta(msg))
+		}
+		return nil
+	}
+	if msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+
+	if d, ok := a.layout.DividerAt(a.w, a.h, a.paneVisible, msg.X, msg.Y); ok {
+		a.drag = d
+		return nil
+	}
+	double := a.doubleClick(msg)
+	a.focusPane(pane)
+	if c, ok := a.paneModel(pane).(clickHandler); ok {
+		return c.Click(msg.X-r.X, msg.Y-r.Y, double)
+	}
+	return nil
+}
+
+// overlayMouse sends mouse events to the open overlay. Clicking outside of
+// it closes it.
+func (a *App) overlayMouse(msg tea.MouseMsg) tea.Cmd {
+	var overlay tea.Model = a.qs
+	if a.help.Active() {
+		overlay = a.help
+	}
+	if tea.MouseEvent(msg).IsWheel() {
+		if s, ok := overlay.(scrollHandler); ok {
+			return s.Scroll(wheelDelta(msg))
+		}
+		return nil
+	}
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	r := layout.Centered(overlay.View(), a.w, a.h)
+	if !r.Contains(msg.X, msg.Y) {
+		a.help.Close()
+		a.qs.SetActive(false)
+	} else if c, ok := overlay.(clickHandler); ok {
+		cmd = c.Click(msg.X-r.X, msg.Y-r.Y, a.doubleClick(msg))
+	}
+	if !a.help.Active() && !a.qs.Active() {
+		a.active = a.editor
+	}
+	return cmd
+}
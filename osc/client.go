@@ -0,0 +1,25 @@
+package osc
+
+import (
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// Client sends OSC messages over UDP, like control values to Tidal
+type Client struct {
+	c *osc.Client
+}
+
+func NewClient(host string, port int) *Client {
+	return &Client{c: osc.NewClient(host, port)}
+}
+
+// SendCtrl sets a control read by Tidal's cF, cI and cS. value must be a
+// float32, int32 or string to match the pattern reading it.
+func (c *Client) SendCtrl(name string, value any) error {
+	return c.c.Send(osc.NewMessage("/ctrl", name, value))
+}
+
+// Addr returns the host and port messages are sent to
+func (c *Client) Addr() (string, int) {
+	return c.c.IP(), c.c.Port()
+}
@@ -8,15 +8,24 @@ import (
 )
 
 type Server struct {
-	srv *osc.Server
-	out chan string
+	srv   *osc.Server
+	out   chan string
+	ticks chan Tick
+}
+
+// Tick is the cycle position and tempo of a played event. Tidal adds cycle
+// and cps to the events of targets using named arguments.
+type Tick struct {
+	Cycle float64
+	CPS   float64
 }
 
 func NewServer(port int) *Server {
 	addr := fmt.Sprintf("127.0.0.1:%d", port)
 	d := osc.NewStandardDispatcher()
 	s := &Server{
-		out: make(chan string, 100),
+		out:   make(chan string, 100),
+		ticks: make(chan Tick, 100),
 		srv: &osc.Server{
 			Addr:       addr,
 			Dispatcher: d,
@@ -41,7 +50,42 @@ func (s *Server) HandlePlay(msg *osc.Message) {
 	default:
 		log.Println("dropped osc message")
 	}
+	if tick, ok := parseTick(msg.Arguments); ok {
+		select {
+		case s.ticks <- tick:
+		default:
+		}
+	}
+}
+
+// parseTick reads the cycle and cps of an event with named arguments,
+// e.g. "s", "bd", "cps", 0.5625, "cycle", 12.25
+func parseTick(args []any) (Tick, bool) {
+	var t Tick
+	var hasCycle, hasCPS bool
+	for i := 0; i+1 < len(args); i++ {
+		name, ok := args[i].(string)
+		if !ok {
+			continue
+		}
+		v, ok := number(args[i+1])
+		if !ok {
+			continue
+		}
+		switch name {
+		case "cycle":
+			t.Cycle, hasCycle = v, true
+		case "cps":
+			t.CPS, hasCPS = v, true
+		}
+	}
+	return t, hasCycle && hasCPS && t.CPS > 0
 }
 func (s *Server) Out() chan string {
 	return s.out
 }
+
+// Ticks receives the cycle position of played events
+func (s *Server) Ticks() chan Tick {
+	return s.ticks
+}
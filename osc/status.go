@@ -0,0 +1,153 @@
+package osc
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// ServerStatus is scsynth's reply to /status
+type ServerStatus struct {
+	UGens     int
+	Synths    int
+	Groups    int
+	SynthDefs int
+	// AvgCPU and PeakCPU are percentages of the audio thread's time
+	AvgCPU  float64
+	PeakCPU float64
+	// NominalSampleRate is the configured rate, ActualSampleRate the
+	// measured one
+	NominalSampleRate float64
+	ActualSampleRate  float64
+}
+
+// StatusClient polls a scsynth server with /status messages
+type StatusClient struct {
+	addr    string
+	timeout time.Duration
+
+	// mu guards conn. It isn't held while a poll waits for its reply, so
+	// Close can close the socket and end the wait.
+	mu   sync.Mutex
+	conn *net.UDPConn
+}
+
+// NewStatusClient creates a client for the server at host:port, waiting
+// up to timeout for each reply
+func NewStatusClient(host string, port int, timeout time.Duration) *StatusClient {
+	return &StatusClient{
+		addr:    net.JoinHostPort(host, fmt.Sprint(port)),
+		timeout: timeout,
+	}
+}
+
+// connect opens the socket replies are sent back to
+func (c *StatusClient) connect() error {
+	if c.conn != nil {
+		return nil
+	}
+	raddr, err := net.ResolveUDPAddr("udp", c.addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+// Status asks the server for its status and waits for the reply
+func (c *StatusClient) Status() (ServerStatus, error) {
+	c.mu.Lock()
+	err := c.connect()
+	conn := c.conn
+	c.mu.Unlock()
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	data, err := osc.NewMessage("/status").MarshalBinary()
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	if _, err := conn.Write(data); err != nil {
+		return ServerStatus{}, err
+	}
+
+	deadline := time.Now().Add(c.timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return ServerStatus{}, err
+	}
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return ServerStatus{}, err
+		}
+		packet, err := osc.ParsePacket(string(buf[:n]))
+		if err != nil {
+			continue
+		}
+		// skip other replies, like a late one from the previous poll
+		if msg, ok := packet.(*osc.Message); ok && msg.Address == "/status.reply" {
+			return parseStatusReply(msg)
+		}
+	}
+}
+
+// Close closes the socket. A later Status opens a new one.
+func (c *StatusClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// parseStatusReply reads the arguments of /status.reply: an unused int,
+// the counts of ugens, synths, groups and synthdefs, the average and peak
+// CPU and the nominal and actual sample rates
+func parseStatusReply(msg *osc.Message) (ServerStatus, error) {
+	if len(msg.Arguments) < 9 {
+		return ServerStatus{}, fmt.Errorf("/status.reply has %d arguments, want 9", len(msg.Arguments))
+	}
+	var nums [9]float64
+	for i := range nums {
+		n, ok := number(msg.Arguments[i])
+		if !ok {
+			return ServerStatus{}, fmt.Errorf("/status.reply argument %d is %T, want a number", i, msg.Arguments[i])
+		}
+		nums[i] = n
+	}
+	return ServerStatus{
+		UGens:             int(nums[1]),
+		Synths:            int(nums[2]),
+		Groups:            int(nums[3]),
+		SynthDefs:         int(nums[4]),
+		AvgCPU:            nums[5],
+		PeakCPU:           nums[6],
+		NominalSampleRate: nums[7],
+		ActualSampleRate:  nums[8],
+	}, nil
+}
+
+func number(arg any) (float64, bool) {
+	switch v := arg.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
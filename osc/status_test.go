@@ -0,0 +1,120 @@
+package osc
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// fakeServer answers each /status sent to it with replies, and returns
+// its port
+func fakeServer(t *testing.T, replies ...*osc.Message) int {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			packet, err := osc.ParsePacket(string(buf[:n]))
+			if msg, ok := packet.(*osc.Message); err != nil || !ok || msg.Address != "/status" {
+				continue
+			}
+			for _, reply := range replies {
+				data, err := reply.MarshalBinary()
+				if err != nil {
+					return
+				}
+				conn.WriteToUDP(data, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestStatus(t *testing.T) {
+	reply := osc.NewMessage("/status.reply",
+		int32(1), int32(42), int32(3), int32(2), int32(120),
+		float32(12.5), float32(31.25), float64(48000), float64(47999.5),
+	)
+	port := fakeServer(t, osc.NewMessage("/done", "/notify"), reply)
+	c := NewStatusClient("127.0.0.1", port, time.Second)
+	defer c.Close()
+
+	got, err := c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ServerStatus{
+		UGens:             42,
+		Synths:            3,
+		Groups:            2,
+		SynthDefs:         120,
+		AvgCPU:            12.5,
+		PeakCPU:           31.25,
+		NominalSampleRate: 48000,
+		ActualSampleRate:  47999.5,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStatusTimeout(t *testing.T) {
+	port := fakeServer(t)
+	c := NewStatusClient("127.0.0.1", port, 50*time.Millisecond)
+	defer c.Close()
+
+	_, err := c.Status()
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got %v, want a timeout", err)
+	}
+}
+
+func TestStatusClose(t *testing.T) {
+	port := fakeServer(t)
+	c := NewStatusClient("127.0.0.1", port, 5*time.Second)
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Status()
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	c.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("got %v, want net.ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close didn't end the poll")
+	}
+}
+
+func TestParseStatusReplyInvalid(t *testing.T) {
+	tests := map[string]*osc.Message{
+		"too few arguments": osc.NewMessage("/status.reply", int32(1), int32(42)),
+		"not a number": osc.NewMessage("/status.reply",
+			int32(1), "42", int32(3), int32(2), int32(120),
+			float32(12.5), float32(31.25), float64(48000), float64(47999.5),
+		),
+	}
+	for name, msg := range tests {
+		if _, err := parseStatusReply(msg); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// soundPatternRe matches the string literal passed to s or sound, e.g. s "bd*2 sn"
+var soundPatternRe = regexp.MustCompile(`(?:^|[^\w'.])(?:s|sound)\s+"([^"]*)"?`)
+
+// sampleWord is a sample name referenced inside a sound pattern
+type sampleWord struct {
+	name string
+	n    int // index after ':' or -1
+	row  int
+	col  int // column of the first character of the word
+	end  int // column after the last character of the word
+}
+
+func isPatternWordChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		return true
+	case c == '-' || c == ':':
+		return !first
+	}
+	return false
+}
+
+// isPatternModifier reports whether c introduces a numeric argument
+// in mini-notation rather than a sound name, e.g. bd*2 or bd(3,8).
+// , and | separate sound names, e.g. [bd,hh] or bd|sn.
+func isPatternModifier(c byte) bool {
+	return strings.IndexByte("*/!@%?(.:^", c) >= 0
+}
+
+// scanPattern calls word with the bounds of each word in mini-notation
+// that isn't part of a modifier argument, and reports whether pat ends
+// inside one. Arguments run to the next space outside brackets, e.g.
+// bd(3, 8) or bd*<2 3>.
+func scanPattern(pat string, word func(start, end int)) bool {
+	inArg := false
+	depth := 0
+	for i := 0; i < len(pat); {
+		c := pat[i]
+		if inArg {
+			switch {
+			case strings.IndexByte("([{<", c) >= 0:
+				depth++
+			case strings.IndexByte(")]}>", c) >= 0 && depth > 0:
+				depth--
+			case c == ' ' || c == '\t':
+				inArg = depth > 0
+			}
+			i++
+			continue
+		}
+		if isPatternModifier(c) {
+			inArg = true
+			if c == '(' {
+				depth++
+			}
+			i++
+			continue
+		}
+		if !isPatternWordChar(c, true) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(pat) && isPatternWordChar(pat[i], i == start) {
+			i++
+		}
+		if word != nil {
+			word(start, i)
+		}
+	}
+	return inArg
+}
+
+// patternWords splits the contents of a mini-notation string into sample words.
+// offset is the column of the string contents within its line. Words
+// starting with a digit or _ are numbers or elongation.
+func patternWords(pat string, row, offset int) []sampleWord {
+	var words []sampleWord
+	scanPattern(pat, func(start, end int) {
+		if c := pat[start]; c == '_' || (c >= '0' && c <= '9') {
+			return
+		}
+		w := sampleWord{
+			name: pat[start:end],
+			n:    -1,
+			row:  row,
+			col:  offset + start,
+			end:  offset + end,
+		}
+		if j := strings.IndexByte(w.name, ':'); j >= 0 {
+			if n, err := strconv.Atoi(w.name[j+1:]); err == nil {
+				w.n = n
+			}
+			w.name = w.name[:j]
+		}
+		if w.name == "" {
+			return
+		}
+		words = append(words, w)
+	})
+	return words
+}
+
+// soundPatternWords returns every sample word used in s/sound patterns in lines.
+// Commented lines are skipped.
+func soundPatternWords(lines []string) []sampleWord {
+	var words []sampleWord
+	for row, line := range lines {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		for _, loc := range soundPatternRe.FindAllStringSubmatchIndex(line, -1) {
+			words = append(words, patternWords(line[loc[2]:loc[3]], row, loc[2])...)
+		}
+	}
+	return words
+}
+
+// soundWordAt returns the partial sample word ending at col when col is
+// inside an s/sound pattern string, along with the column it starts at.
+func soundWordAt(line string, col int) (word string, start int, ok bool) {
+	if col > len(line) {
+		col = len(line)
+	}
+	for _, loc := range soundPatternRe.FindAllStringSubmatchIndex(line[:col], -1) {
+		// the string must still be open at the cursor
+		if loc[3] != col {
+			continue
+		}
+		start = col
+		for start > loc[2] && isPatternWordChar(line[start-1], false) {
+			start--
+		}
+		for start < col && !isPatternWordChar(line[start], true) {
+			start++
+		}
+		if scanPattern(line[loc[2]:start], nil) {
+			return "", 0, false
+		}
+		return line[start:col], start, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPatternWords(t *testing.T) {
+	tests := []struct {
+		pat  string
+		want []string
+	}{
+		{pat: "bd sn", want: []string{"bd", "sn"}},
+		{pat: "bd*2 [sn cp]", want: []string{"bd", "sn", "cp"}},
+		{pat: "bd(3,8) sn", want: []string{"bd", "sn"}},
+		{pat: "bd(3, 8) sn", want: []string{"bd", "sn"}},
+		{pat: "bd(<3 5>, 8, <0 2>) hh", want: []string{"bd", "hh"}},
+		{pat: "bd*<2 3> sn", want: []string{"bd", "sn"}},
+		{pat: "bd _ _ sn", want: []string{"bd", "sn"}},
+		{pat: "bd 808 sn", want: []string{"bd", "sn"}},
+		{pat: "{bd sn, hh hh hh}%4", want: []string{"bd", "sn", "hh", "hh", "hh"}},
+		{pat: "bd:3 ~ sn? drum-808", want: []string{"bd", "sn", "drum-808"}},
+		{pat: "[bd, hh] sn!", want: []string{"bd", "hh", "sn"}},
+		{pat: "[bd,hh]", want: []string{"bd", "hh"}},
+		{pat: "bd|sn", want: []string{"bd", "sn"}},
+		{pat: "bd(3,8,2)|sn", want: []string{"bd"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, w := range patternWords(tt.pat, 0, 0) {
+			got = append(got, w.name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("patternWords(%q) = %q, want %q", tt.pat, got, tt.want)
+		}
+	}
+}
+
+func TestPatternWordsColumns(t *testing.T) {
+	words := patternWords("bd(3, 8) sn:2", 4, 10)
+	want := []sampleWord{
+		{name: "bd", n: -1, row: 4, col: 10, end: 12},
+		{name: "sn", n: 2, row: 4, col: 19, end: 23},
+	}
+	if !slices.Equal(words, want) {
+		t.Errorf("got %+v, want %+v", words, want)
+	}
+}
+
+func TestSoundWordAt(t *testing.T) {
+	tests := []struct {
+		line string
+		word string
+		ok   bool
+	}{
+		{line: `d1 $ s "bd h`, word: "h", ok: true},
+		{line: `d1 $ s "[bd,h`, word: "h", ok: true},
+		{line: `d1 $ s "bd|s`, word: "s", ok: true},
+		{line: `d1 $ s "bd*`, ok: false},
+		{line: `d1 $ s "bd(3,`, ok: false},
+		{line: `d1 $ s "bd(3, 8`, ok: false},
+		{line: `d1 $ s "bd*<2 3`, ok: false},
+		{line: `d1 $ s "bd(3,8) s`, word: "s", ok: true},
+		{line: `d1 $ s "bd" # n`, ok: false},
+	}
+	for _, tt := range tests {
+		word, _, ok := soundWordAt(tt.line, len(tt.line))
+		if ok != tt.ok || word != tt.word {
+			t.Errorf("soundWordAt(%q) = %q, %v, want %q, %v", tt.line, word, ok, tt.word, tt.ok)
+		}
+	}
+}
@@ -1,92 +1,92 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
 )
 
-var (
-	dialogBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#874BFD")).
-			Padding(1, 0).
-			BorderTop(true).
-			BorderLeft(true).
-			BorderRight(true).
-			BorderBottom(true)
-
-	subtle    = lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"}
-	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
-	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
-)
+type quickSelectKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Close  key.Binding
+}
 
-func renderDialog(text string, w, h int) string {
-	return lipgloss.Place(w, h,
-		lipgloss.Center, lipgloss.Center,
-		dialogBoxStyle.Render(text),
-		// lipgloss.WithWhitespaceChars("猫咪"),
-		lipgloss.WithWhitespaceForeground(subtle),
-	)
+var defaultQuickSelectKeyMap = quickSelectKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "ctrl+p"),
+		key.WithHelp("↑/ctrl+p", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "ctrl+n"),
+		key.WithHelp("↓/ctrl+n", "down"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "run command"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
 }
 
+// commandSource adapts commands to fuzzy.Source
+type commandSource []command
+
+func (s commandSource) String(i int) string { return s[i].name }
+func (s commandSource) Len() int            { return len(s) }
+
+// QuickSelect is the command palette, fuzzy filtering every app command
 type QuickSelect struct {
 	active   bool
-	l        *list.Model
+	input    textinput.Model
+	commands commandSource
+	results  []finderResult
+	cursor   int
+	offset   int
 	w, h     int
-	onSelect func(i *selectItem) tea.Cmd
-}
+	onSelect func(c command, arg string) tea.Cmd
 
-type selectItem struct {
-	name  string
-	value string
-}
-
-func (m *selectItem) FilterValue() string {
-	return m.name
-}
-func (m *selectItem) View() string {
-	return m.value
-}
-func (m *selectItem) Title() string {
-	return m.name
-}
-
-func (i *selectItem) Description() string {
-	return ""
+	// pending is the command waiting for its argument
+	pending *command
+	arg     textinput.Model
 }
 
 func NewQuickSelect() *QuickSelect {
-	d := list.NewDefaultDelegate()
-	d.SetHeight(1)
-	d.ShowDescription = true
+	input := textinput.New()
+	input.Placeholder = "Run command"
+	input.Prompt = "> "
+	input.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
 
-	l := list.New([]list.Item{}, d, 0, 0)
-	l.KeyMap.CursorUp.SetKeys("k", "up")
-	l.KeyMap.CursorDown.SetKeys("j", "down")
-	l.KeyMap.Quit.SetKeys("ctrl+c")
+	arg := textinput.New()
+	arg.PromptStyle = input.PromptStyle
 
-	l.Title = "View Console"
-	l.SetShowTitle(true)
-	l.SetFilteringEnabled(true)
-	l.SetShowFilter(true)
-	l.SetShowHelp(true)
-	l.SetShowStatusBar(true)
-	l.SetShowPagination(true)
-
-	return &QuickSelect{l: &l}
+	return &QuickSelect{input: input, arg: arg}
 }
 
-func (m *QuickSelect) SetOnSelect(f func(i *selectItem) tea.Cmd) {
+func (m *QuickSelect) SetOnSelect(f func(c command, arg string) tea.Cmd) {
 	m.onSelect = f
 }
 
+// SetSize sets the screen size. The palette takes half of it.
 func (m *QuickSelect) SetSize(w, h int) {
 	m.w = w
 	m.h = h
-	m.l.SetSize(w, h)
+	m.input.Width = m.width() - 4
+	m.arg.Width = m.width() - 4
+}
+
+// width is the width of the palette's content
+func (m *QuickSelect) width() int {
+	return max(m.w/2, 10)
 }
 func (m *QuickSelect) Active() bool {
 	return m.active
@@ -95,34 +95,154 @@ func (m *QuickSelect) SetActive(active bool) {
 	m.active = active
 }
 
-func (m *QuickSelect) Init() tea.Cmd {
-	items := []list.Item{
-		&selectItem{name: "Tidal Repl", value: "tidalrepl"},
-		&selectItem{name: "SCLang", value: "sclang"},
+// CapturingInput is true while open so typing doesn't trigger global keys
+func (m *QuickSelect) CapturingInput() bool {
+	return m.active
+}
+
+// Open shows the palette listing commands
+func (m *QuickSelect) Open(commands []command) tea.Cmd {
+	return m.Pick("Run command", commands)
+}
+
+// Pick shows the palette listing a subset of commands, like the snippets,
+// with placeholder as the input's hint
+func (m *QuickSelect) Pick(placeholder string, commands []command) tea.Cmd {
+	m.input.Placeholder = placeholder
+	m.active = true
+	m.pending = nil
+	m.commands = commands
+	m.input.Reset()
+	m.filter()
+	return m.input.Focus()
+}
+
+func (m *QuickSelect) filter() {
+	m.results = m.results[:0]
+	m.cursor, m.offset = 0, 0
+
+	q := strings.TrimSpace(m.input.Value())
+	if q == "" {
+		for i := range m.commands {
+			m.results = append(m.results, finderResult{entry: i})
+		}
+		return
+	}
+	for _, match := range fuzzy.FindFrom(q, m.commands) {
+		m.results = append(m.results, finderResult{
+			entry:   match.Index,
+			matched: match.MatchedIndexes,
+			score:   match.Score,
+		})
+	}
+}
+
+func (m *QuickSelect) visibleRows() int {
+	// input and count lines
+	return max(m.h/2-2, 1)
+}
+
+func (m *QuickSelect) move(delta int) {
+	if len(m.results) == 0 {
+		return
+	}
+	m.cursor = max(0, min(m.cursor+delta, len(m.results)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.visibleRows() {
+		m.offset = m.cursor - m.visibleRows() + 1
+	}
+}
+
+// run runs c, first prompting for its argument if it takes one
+func (m *QuickSelect) run(c command, arg string) tea.Cmd {
+	if c.arg != "" && m.pending == nil {
+		m.pending = &c
+		m.arg.Prompt = c.arg + ": "
+		m.arg.Reset()
+		return m.arg.Focus()
+	}
+	m.active = false
+	m.pending = nil
+	if m.onSelect == nil {
+		log.Println("selected command:", c.name)
+		return nil
 	}
-	return m.l.SetItems(items)
+	return m.onSelect(c, arg)
+}
+
+// Click runs the clicked command
+func (m *QuickSelect) Click(x, y int, double bool) tea.Cmd {
+	if m.pending != nil {
+		return nil
+	}
+	// the dialog's border and padding, then the input line
+	i := m.offset + y - 3
+	if y < 3 || i >= min(m.offset+m.visibleRows(), len(m.results)) {
+		return nil
+	}
+	m.cursor = i
+	return m.run(m.commands[m.results[i].entry], "")
+}
+
+// Scroll moves the cursor by wheel steps
+func (m *QuickSelect) Scroll(delta int) tea.Cmd {
+	if m.pending == nil {
+		m.move(delta)
+	}
+	return nil
+}
+
+func (m *QuickSelect) Init() tea.Cmd {
+	return nil
 }
 
 func (m *QuickSelect) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		if !m.active {
-			return m, nil
-		}
-		if msg.String() == "enter" {
-			if m.onSelect != nil {
-				return m, m.onSelect(m.l.SelectedItem().(*selectItem))
+	if !m.active {
+		return m, nil
+	}
+
+	if m.pending != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, defaultQuickSelectKeyMap.Close):
+				m.pending = nil
+				return m, m.input.Focus()
+			case key.Matches(msg, defaultQuickSelectKeyMap.Select):
+				return m, m.run(*m.pending, strings.TrimSpace(m.arg.Value()))
 			}
-			currentItem, ok := m.l.SelectedItem().(*selectItem)
-			if !ok {
-				log.Println("no item selected")
+		}
+		var cmd tea.Cmd
+		m.arg, cmd = m.arg.Update(msg)
+		return m, cmd
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, defaultQuickSelectKeyMap.Close):
+			m.active = false
+			return m, nil
+		case key.Matches(msg, defaultQuickSelectKeyMap.Up):
+			m.move(-1)
+			return m, nil
+		case key.Matches(msg, defaultQuickSelectKeyMap.Down):
+			m.move(1)
+			return m, nil
+		case key.Matches(msg, defaultQuickSelectKeyMap.Select):
+			if m.cursor >= len(m.results) {
 				return m, nil
 			}
-			log.Println("selected item:", currentItem.name)
+			return m, m.run(m.commands[m.results[m.cursor].entry], "")
 		}
 	}
-	l, cmd := m.l.Update(msg)
-	m.l = &l
+
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.filter()
+	}
 	return m, cmd
 }
 
@@ -130,5 +250,36 @@ func (m *QuickSelect) View() string {
 	if !m.active {
 		return ""
 	}
-	return renderDialog(m.l.View(), m.w, m.h)
+	width := m.width()
+
+	if m.pending != nil {
+		lines := []string{
+			finderMatchStyle.Render(m.pending.name),
+			m.arg.View(),
+			completionStyle.Render("enter to run, esc to go back"),
+		}
+		return dialogBoxStyle.Render(lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n")))
+	}
+
+	lines := []string{m.input.View()}
+	end := min(m.offset+m.visibleRows(), len(m.results))
+	for i := m.offset; i < end; i++ {
+		r := m.results[i]
+		c := m.commands[r.entry]
+		style := finderItemStyle
+		if i == m.cursor {
+			style = finderSelectedStyle
+		}
+		keys := c.Keys()
+		name := truncate(c.name, max(width-lipgloss.Width(keys)-2, 4))
+		gap := max(width-lipgloss.Width(name)-lipgloss.Width(keys), 1)
+		lines = append(lines, highlightMatches(name, r.matched, style)+
+			style.Render(strings.Repeat(" ", gap))+
+			completionStyle.Render(keys))
+	}
+	for i := end - m.offset; i < m.visibleRows(); i++ {
+		lines = append(lines, "")
+	}
+	lines = append(lines, completionStyle.Render(fmt.Sprintf("%d/%d commands", len(m.results), len(m.commands))))
+	return dialogBoxStyle.Render(lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n")))
 }
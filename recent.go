@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxRecentFiles limits how many opened files are remembered
+const maxRecentFiles = 10
+
+// recentFiles remembers recently opened files, most recent first
+type recentFiles struct {
+	path  string
+	files []string
+}
+
+// loadRecentFiles reads the list at path. A missing file is an empty list.
+func loadRecentFiles(path string) *recentFiles {
+	r := &recentFiles{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &r.files)
+	}
+	if err != nil {
+		log.Println("failed to load recent files:", err)
+	}
+	return r
+}
+
+// Add moves path to the front of the list and saves it in the background
+func (r *recentFiles) Add(path string) tea.Cmd {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	files := []string{path}
+	for _, f := range r.files {
+		if f != path && len(files) < maxRecentFiles {
+			files = append(files, f)
+		}
+	}
+	r.files = files
+
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+			log.Println("failed to save recent files:", err)
+			return nil
+		}
+		if err := os.WriteFile(r.path, data, 0644); err != nil {
+			log.Println("failed to save recent files:", err)
+		}
+		return nil
+	}
+}
+
+func (r *recentFiles) Files() []string {
+	return r.files
+}
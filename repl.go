@@ -9,21 +9,24 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // TidalRepl starts the tidal process and sends commands to it via stdin and captures its output via stdout.
 type TidalRepl struct {
+	// mu guards cmd and stdin, which a restart replaces while code may be sent
+	mu       sync.Mutex
 	cmd      *exec.Cmd
 	stdin    io.WriteCloser
 	stdout   io.ReadCloser
 	stderr   io.ReadCloser
-	out      chan string
+	out      chan outputLine
 	bootFile string // Path to the boot file, if any
 }
 
 func NewTidalRepl(bootFile string) *TidalRepl {
 	return &TidalRepl{
-		out:      make(chan string, 100),
+		out:      make(chan outputLine, 100),
 		bootFile: expandPath(bootFile),
 	}
 }
@@ -39,6 +42,12 @@ func (r *TidalRepl) buildCmd() *exec.Cmd {
 }
 
 func (r *TidalRepl) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.start()
+}
+
+func (r *TidalRepl) start() error {
 	if r.bootFile == "" {
 		r.bootFile, _ = findFileUpwards("BootTidal.hs")
 	}
@@ -66,12 +75,18 @@ func (r *TidalRepl) Start() error {
 		return err
 	}
 
-	go r.readOutput(r.stdout)
-	go r.readOutput(r.stderr)
+	go r.readOutput(r.stdout, streamStdout)
+	go r.readOutput(r.stderr, streamStderr)
 	return nil
 }
 
 func (r *TidalRepl) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stop()
+}
+
+func (r *TidalRepl) stop() error {
 	log.Println("Stopping Tidal REPL...")
 	if r.stdin != nil {
 		r.stdin.Close()
@@ -83,23 +98,48 @@ func (r *TidalRepl) Stop() error {
 	return nil
 }
 
-func (r *TidalRepl) readOutput(reader io.Reader) {
+// Restart stops the process, if running, and starts a new one. The old
+// process is reaped after the lock is released, so sends don't wait on it.
+func (r *TidalRepl) Restart() error {
+	r.mu.Lock()
+	old := r.cmd
+	if old != nil {
+		r.stop()
+	}
+	err := r.start()
+	r.mu.Unlock()
+	reapProcess(old)
+	return err
+}
+
+// reapProcess waits for a stopped REPL process to exit, if it was started.
+// Its exit error is expected.
+func reapProcess(cmd *exec.Cmd) {
+	if cmd != nil && cmd.Process != nil {
+		cmd.Wait()
+	}
+}
+
+func (r *TidalRepl) readOutput(reader io.Reader, stream lineStream) {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		r.out <- scanner.Text()
+		r.out <- outputLine{stream: stream, text: scanner.Text()}
 	}
 }
 
-func (r *TidalRepl) Output() <-chan string {
+func (r *TidalRepl) Output() <-chan outputLine {
 	return r.out
 }
 
 func (r *TidalRepl) Send(cmd string) error {
 	escaped := r.escapeText(cmd)
-	if _, err := r.stdin.Write([]byte(escaped)); err != nil {
+	r.mu.Lock()
+	_, err := r.stdin.Write([]byte(escaped))
+	r.mu.Unlock()
+	if err != nil {
 		return err
 	}
-	r.out <- cmd
+	r.out <- outputLine{stream: streamInput, text: cmd}
 	return nil
 }
 
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sampleKey identifies a sample independent of where the library is mounted
+func sampleKey(bank, name string) string {
+	return bank + "/" + name
+}
+
+type sampleMeta struct {
+	Favorite bool     `json:"favorite,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// SampleDB stores favorites, tags and collections of samples in a json file
+type SampleDB struct {
+	path string
+	mu   sync.Mutex
+	// loadErr keeps a file that failed to load from being overwritten
+	loadErr error
+	// saveMu serializes writing the file, saveTimer batches SaveLater calls
+	saveMu    sync.Mutex
+	saveTimer *time.Timer
+	// dirty is set by SaveLater until a save has taken a copy of the changes
+	dirty bool
+
+	Samples     map[string]*sampleMeta `json:"samples"`
+	Collections map[string][]string    `json:"collections"`
+	// Used holds the unix time each sample was last played, inserted or yanked
+	Used map[string]int64 `json:"used"`
+}
+
+func NewSampleDB(path string) *SampleDB {
+	return &SampleDB{
+		path:        path,
+		Samples:     make(map[string]*sampleMeta),
+		Collections: make(map[string][]string),
+		Used:        make(map[string]int64),
+	}
+}
+
+// LoadSampleDB reads the database at path. A missing file is an empty database.
+// A database that fails to load is empty and never saved, so the file can
+// be fixed by hand.
+func LoadSampleDB(path string) (*SampleDB, error) {
+	db := NewSampleDB(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		db.loadErr = err
+		return db, err
+	}
+	if err := json.Unmarshal(data, db); err != nil {
+		db = NewSampleDB(path)
+		db.loadErr = err
+		return db, err
+	}
+	if db.Samples == nil {
+		db.Samples = make(map[string]*sampleMeta)
+	}
+	if db.Collections == nil {
+		db.Collections = make(map[string][]string)
+	}
+	if db.Used == nil {
+		db.Used = make(map[string]int64)
+	}
+	return db, nil
+}
+
+// sampleDBSaveDelay is how long SaveLater waits for more changes
+const sampleDBSaveDelay = time.Second
+
+// Save writes the database, replacing the previous file atomically
+func (db *SampleDB) Save() error {
+	if db.loadErr != nil {
+		return fmt.Errorf("not overwriting %s, it failed to load: %w", db.path, db.loadErr)
+	}
+	db.saveMu.Lock()
+	defer db.saveMu.Unlock()
+	db.mu.Lock()
+	data, err := json.MarshalIndent(db, "", "  ")
+	db.dirty = false
+	db.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0755); err != nil {
+		return err
+	}
+	tmp := db.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, db.path)
+}
+
+// SaveLater saves the database in the background once it stops changing
+// for a moment, so a burst of plays writes the file once
+func (db *SampleDB) SaveLater() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.dirty = true
+	if db.saveTimer != nil {
+		db.saveTimer.Stop()
+	}
+	db.saveTimer = time.AfterFunc(sampleDBSaveDelay, func() {
+		if err := db.Save(); err != nil {
+			log.Println("Error saving sample db:", err)
+		}
+	})
+}
+
+// Flush saves changes still waiting on SaveLater, or waits for a save
+// that already copied them to finish writing
+func (db *SampleDB) Flush() error {
+	db.mu.Lock()
+	if db.saveTimer != nil {
+		db.saveTimer.Stop()
+		db.saveTimer = nil
+	}
+	dirty := db.dirty
+	db.mu.Unlock()
+	if dirty {
+		return db.Save()
+	}
+	db.saveMu.Lock()
+	db.saveMu.Unlock()
+	return nil
+}
+
+func (db *SampleDB) meta(key string) *sampleMeta {
+	m, ok := db.Samples[key]
+	if !ok {
+		m = &sampleMeta{}
+		db.Samples[key] = m
+	}
+	return m
+}
+
+// prune drops entries without any metadata
+func (db *SampleDB) prune(key string) {
+	if m, ok := db.Samples[key]; ok && !m.Favorite && len(m.Tags) == 0 {
+		delete(db.Samples, key)
+	}
+}
+
+// ToggleFavorite stars or unstars a sample and returns whether it's now a favorite
+func (db *SampleDB) ToggleFavorite(key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	m := db.meta(key)
+	m.Favorite = !m.Favorite
+	db.prune(key)
+	return m.Favorite
+}
+
+func (db *SampleDB) IsFavorite(key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	m, ok := db.Samples[key]
+	return ok && m.Favorite
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (db *SampleDB) AddTag(key, tag string) {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	m := db.meta(key)
+	for _, t := range m.Tags {
+		if t == tag {
+			return
+		}
+	}
+	m.Tags = append(m.Tags, tag)
+	sort.Strings(m.Tags)
+}
+
+func (db *SampleDB) RemoveTag(key, tag string) {
+	tag = normalizeTag(tag)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	m, ok := db.Samples[key]
+	if !ok {
+		return
+	}
+	for i, t := range m.Tags {
+		if t == tag {
+			m.Tags = append(m.Tags[:i], m.Tags[i+1:]...)
+			break
+		}
+	}
+	db.prune(key)
+}
+
+func (db *SampleDB) Tags(key string) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if m, ok := db.Samples[key]; ok {
+		return m.Tags
+	}
+	return nil
+}
+
+func (db *SampleDB) HasTag(key, tag string) bool {
+	for _, t := range db.Tags(key) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *SampleDB) AddToCollection(name, key string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, k := range db.Collections[name] {
+		if k == key {
+			return
+		}
+	}
+	db.Collections[name] = append(db.Collections[name], key)
+}
+
+func (db *SampleDB) RemoveFromCollection(name, key string) {
+	name = strings.TrimSpace(name)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	keys := db.Collections[name]
+	for i, k := range keys {
+		if k == key {
+			db.Collections[name] = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(db.Collections[name]) == 0 {
+		delete(db.Collections, name)
+	}
+}
+
+func (db *SampleDB) InCollection(name, key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, k := range db.Collections[name] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Touch records that a sample was just used
+func (db *SampleDB) Touch(key string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.Used[key] = time.Now().Unix()
+}
+
+// LastUsed returns when a sample was last used, or the zero time
+func (db *SampleDB) LastUsed(key string) time.Time {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if t, ok := db.Used[key]; ok {
+		return time.Unix(t, 0)
+	}
+	return time.Time{}
+}
+
+// sampleQuery is a parsed sample filter, e.g. "tag:kick fav: coll:set1 808"
+type sampleQuery struct {
+	words       []string
+	tags        []string
+	collections []string
+	fav         bool
+}
+
+func parseSampleQuery(q string) sampleQuery {
+	var query sampleQuery
+	for _, term := range strings.Fields(strings.ToLower(q)) {
+		switch {
+		case term == "fav:":
+			query.fav = true
+		case strings.HasPrefix(term, "tag:") && len(term) > 4:
+			query.tags = append(query.tags, term[4:])
+		case strings.HasPrefix(term, "coll:") && len(term) > 5:
+			query.collections = append(query.collections, term[5:])
+		default:
+			query.words = append(query.words, term)
+		}
+	}
+	return query
+}
+
+// HasMeta reports whether the query filters on favorites, tags or collections
+func (q sampleQuery) HasMeta() bool {
+	return q.fav || len(q.tags) > 0 || len(q.collections) > 0
+}
+
+// MatchText reports whether s contains every plain word of the query
+func (q sampleQuery) MatchText(s string) bool {
+	s = strings.ToLower(s)
+	for _, w := range q.words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchMeta reports whether the sample satisfies every metadata term of the query
+func (q sampleQuery) MatchMeta(db *SampleDB, key string) bool {
+	if !q.HasMeta() {
+		return true
+	}
+	if db == nil {
+		return false
+	}
+	if q.fav && !db.IsFavorite(key) {
+		return false
+	}
+	for _, t := range q.tags {
+		if !db.HasTag(key, t) {
+			return false
+		}
+	}
+	for _, c := range q.collections {
+		if !db.inCollectionFold(c, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// inCollectionFold is InCollection with a case-insensitive collection name
+func (db *SampleDB) inCollectionFold(name, key string) bool {
+	db.mu.Lock()
+	var names []string
+	for n := range db.Collections {
+		if strings.EqualFold(n, name) {
+			names = append(names, n)
+		}
+	}
+	db.mu.Unlock()
+	for _, n := range names {
+		if db.InCollection(n, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSampleDBFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.json")
+	db := NewSampleDB(path)
+	db.ToggleFavorite(sampleKey("bd", "kick.wav"))
+	db.SaveLater()
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSampleDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.IsFavorite(sampleKey("bd", "kick.wav")) {
+		t.Error("favorite wasn't saved")
+	}
+}
+
+func TestSampleDBNotSavedAfterLoadError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadSampleDB(path)
+	if err == nil {
+		t.Fatal("no load error")
+	}
+	db.ToggleFavorite(sampleKey("bd", "kick.wav"))
+	if err := db.Save(); err == nil {
+		t.Error("saved over a database that failed to load")
+	}
+}
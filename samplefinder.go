@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+type sampleFinderKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Play   key.Binding
+	Reveal key.Binding
+	Insert key.Binding
+	Yank   key.Binding
+	Close  key.Binding
+}
+
+var defaultSampleFinderKeyMap = sampleFinderKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "ctrl+p"),
+		key.WithHelp("↑/ctrl+p", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "ctrl+n"),
+		key.WithHelp("↓/ctrl+n", "down"),
+	),
+	Play: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "play sample"),
+	),
+	Reveal: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "show in bank"),
+	),
+	Insert: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "insert ref at cursor"),
+	),
+	Yank: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "yank ref"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close finder"),
+	),
+}
+
+// finderEntry is a sample searchable by the finder
+type finderEntry struct {
+	bank string
+	n    int
+	file audioFile
+	text string
+}
+
+type finderResult struct {
+	entry   int
+	matched []int
+	score   int
+}
+
+// finderSource adapts entries to fuzzy.Source
+type finderSource []finderEntry
+
+func (s finderSource) String(i int) string { return s[i].text }
+func (s finderSource) Len() int            { return len(s) }
+
+// recencyBonus favors recently used samples, decaying over a day
+func recencyBonus(used time.Time) int {
+	if used.IsZero() {
+		return 0
+	}
+	age := time.Since(used)
+	switch {
+	case age < time.Hour:
+		return 30
+	case age < 6*time.Hour:
+		return 20
+	case age < 24*time.Hour:
+		return 10
+	}
+	return 0
+}
+
+// sampleFinder fuzzy searches every sample of every bank
+type sampleFinder struct {
+	input   textinput.Model
+	entries finderSource
+	results []finderResult
+	cursor  int
+	offset  int
+	w, h    int
+	db      *SampleDB
+
+	// title replaces the search input when showing fixed results
+	title string
+}
+
+func newSampleFinder() *sampleFinder {
+	input := textinput.New()
+	input.Placeholder = "Find sample"
+	input.Prompt = "🔎 "
+	input.PromptStyle = lipgloss.NewStyle().Foreground(theme.Info)
+	return &sampleFinder{input: input}
+}
+
+func (f *sampleFinder) SetSize(width, height int) {
+	f.w = width
+	f.h = height
+	f.input.Width = width - 5
+}
+
+// SetFiles rebuilds the searchable entries in bank and index order
+func (f *sampleFinder) SetFiles(banks []string, files map[string][]audioFile) {
+	f.entries = f.entries[:0]
+	f.title = ""
+	for _, bank := range banks {
+		for i, file := range files[bank] {
+			f.entries = append(f.entries, finderEntry{
+				bank: bank,
+				n:    i,
+				file: file,
+				text: fmt.Sprintf("%s %s", sampleRef(bank, i), file.name),
+			})
+		}
+	}
+	f.search()
+}
+
+func (f *sampleFinder) Open() tea.Cmd {
+	f.title = ""
+	f.input.Reset()
+	f.search()
+	return f.input.Focus()
+}
+
+// Search opens the finder with a query, e.g. a sound name SuperDirt
+// couldn't find
+func (f *sampleFinder) Search(query string) tea.Cmd {
+	f.title = ""
+	f.input.SetValue(query)
+	f.input.CursorEnd()
+	f.search()
+	return f.input.Focus()
+}
+
+// ShowResults lists the given entries in order instead of search results
+func (f *sampleFinder) ShowResults(title string, entries []int) {
+	f.title = title
+	f.input.Blur()
+	f.results = f.results[:0]
+	f.cursor, f.offset = 0, 0
+	for _, i := range entries {
+		f.results = append(f.results, finderResult{entry: i})
+	}
+}
+
+func (f *sampleFinder) lastUsed(e finderEntry) time.Time {
+	if f.db == nil {
+		return time.Time{}
+	}
+	return f.db.LastUsed(sampleKey(e.bank, e.file.name))
+}
+
+// search ranks entries by fuzzy match score plus a recency bonus.
+// An empty query lists recently used samples.
+func (f *sampleFinder) search() {
+	f.results = f.results[:0]
+	f.cursor, f.offset = 0, 0
+
+	q := strings.TrimSpace(f.input.Value())
+	if q == "" {
+		for i, e := range f.entries {
+			if !f.lastUsed(e).IsZero() {
+				f.results = append(f.results, finderResult{entry: i})
+			}
+		}
+		sort.SliceStable(f.results, func(i, j int) bool {
+			return f.lastUsed(f.entries[f.results[i].entry]).After(f.lastUsed(f.entries[f.results[j].entry]))
+		})
+		return
+	}
+
+	for _, m := range fuzzy.FindFrom(q, f.entries) {
+		f.results = append(f.results, finderResult{
+			entry:   m.Index,
+			matched: m.MatchedIndexes,
+			score:   m.Score + recencyBonus(f.lastUsed(f.entries[m.Index])),
+		})
+	}
+	sort.SliceStable(f.results, func(i, j int) bool {
+		return f.results[i].score > f.results[j].score
+	})
+}
+
+// Selected returns the entry under the cursor
+func (f *sampleFinder) Selected() (finderEntry, bool) {
+	if f.cursor >= len(f.results) {
+		return finderEntry{}, false
+	}
+	return f.entries[f.results[f.cursor].entry], true
+}
+
+func (f *sampleFinder) visibleRows() int {
+	// title, input and count lines
+	return max(f.h-3, 1)
+}
+
+func (f *sampleFinder) move(delta int) {
+	if len(f.results) == 0 {
+		return
+	}
+	f.cursor = max(0, min(f.cursor+delta, len(f.results)-1))
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+f.visibleRows() {
+		f.offset = f.cursor - f.visibleRows() + 1
+	}
+}
+
+// Click moves the cursor to the result on line y, reporting whether there is one
+func (f *sampleFinder) Click(y int) bool {
+	// below the input line
+	i := f.offset + y - 1
+	if y < 1 || i >= min(f.offset+f.visibleRows(), len(f.results)) {
+		return false
+	}
+	f.cursor = i
+	return true
+}
+
+// Update handles navigation and text input. Actions are handled by the AudioBrowser.
+func (f *sampleFinder) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, defaultSampleFinderKeyMap.Up):
+			f.move(-1)
+			return nil
+		case key.Matches(msg, defaultSampleFinderKeyMap.Down):
+			f.move(1)
+			return nil
+		}
+	}
+	if f.title != "" {
+		return nil
+	}
+	prev := f.input.Value()
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	if f.input.Value() != prev {
+		f.search()
+	}
+	return cmd
+}
+
+// highlightMatches renders text with the fuzzy matched characters emphasized
+func highlightMatches(text string, matched []int, base lipgloss.Style) string {
+	if len(matched) == 0 {
+		return base.Render(text)
+	}
+	hl := make(map[int]struct{}, len(matched))
+	for _, i := range matched {
+		hl[i] = struct{}{}
+	}
+	var sb strings.Builder
+	for i, r := range text {
+		if _, ok := hl[i]; ok {
+			sb.WriteString(finderMatchStyle.Inherit(base).Render(string(r)))
+			continue
+		}
+		sb.WriteString(base.Render(string(r)))
+	}
+	return sb.String()
+}
+
+func (f *sampleFinder) View() string {
+	lines := []string{f.input.View()}
+	if f.title != "" {
+		lines = []string{finderMatchStyle.Render(f.title)}
+	}
+
+	end := min(f.offset+f.visibleRows(), len(f.results))
+	for i := f.offset; i < end; i++ {
+		r := f.results[i]
+		text := truncate(f.entries[r.entry].text, max(f.w-2, 4))
+		style := finderItemStyle
+		if i == f.cursor {
+			style = finderSelectedStyle
+		}
+		lines = append(lines, highlightMatches(text, r.matched, style))
+	}
+	for i := end - f.offset; i < f.visibleRows(); i++ {
+		lines = append(lines, "")
+	}
+
+	count := fmt.Sprintf("%d/%d samples", len(f.results), len(f.entries))
+	if f.title != "" {
+		count = fmt.Sprintf("%d samples", len(f.results))
+	} else if f.input.Value() == "" {
+		count = fmt.Sprintf("%d recent", len(f.results))
+	}
+	lines = append(lines, completionStyle.Render(count))
+	return lipgloss.NewStyle().Width(f.w).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sampleIndexVersion is bumped when the index format or its features change
+const sampleIndexVersion = 1
+
+// indexScanBatch is how many bank folders are checked before reporting progress
+const indexScanBatch = 20
+
+// indexedFile is a sound file recorded in the sample index
+type indexedFile struct {
+	Name     string          `json:"name"`
+	Size     int64           `json:"size"`
+	ModTime  int64           `json:"mtime"`
+	Features *sampleFeatures `json:"features,omitempty"`
+}
+
+// unchanged reports whether the file on disk still matches the index
+func (f indexedFile) unchanged(info fs.FileInfo) bool {
+	return f.Size == info.Size() && f.ModTime == info.ModTime().UnixNano()
+}
+
+// indexedFolder is a bank folder recorded in the sample index
+type indexedFolder struct {
+	// ModTime of the folder, which changes when files are added or removed
+	ModTime int64         `json:"mtime"`
+	Files   []indexedFile `json:"files"`
+}
+
+func (f *indexedFolder) file(name string) (*indexedFile, bool) {
+	for i := range f.Files {
+		if f.Files[i].Name == name {
+			return &f.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+// clone copies the folder so it can be read while the original is updated
+func (f *indexedFolder) clone() *indexedFolder {
+	return &indexedFolder{
+		ModTime: f.ModTime,
+		Files:   append([]indexedFile(nil), f.Files...),
+	}
+}
+
+// audioFiles converts the folder's files to browser entries
+func (f *indexedFolder) audioFiles(dir string) []audioFile {
+	files := make([]audioFile, len(f.Files))
+	for i, file := range f.Files {
+		path := filepath.Join(dir, file.Name)
+		files[i] = audioFile{
+			path:     path,
+			name:     file.Name,
+			fileType: getFileType(path),
+			size:     formatSize(file.Size),
+		}
+	}
+	return files
+}
+
+// scanSampleFolder lists the sound files directly inside a bank folder,
+// sorted by path as pathMatch returns them. Nested folders are ignored.
+func scanSampleFolder(dir string, exts map[string]struct{}) (*indexedFolder, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &indexedFolder{ModTime: info.ModTime().UnixNano()}
+	for _, entry := range entries {
+		if isHidden(entry.Name()) {
+			continue
+		}
+		if _, ok := exts[strings.ToLower(filepath.Ext(entry.Name()))]; !ok {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, entry.Name()))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		folder.Files = append(folder.Files, indexedFile{
+			Name:    entry.Name(),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	// entries share the folder, so sorting names sorts paths
+	sort.Slice(folder.Files, func(i, j int) bool {
+		return folder.Files[i].Name < folder.Files[j].Name
+	})
+	return folder, nil
+}
+
+// revalidateFolder reuses the indexed folder when nothing was added or removed,
+// only checking its files for edits. Features of unchanged files are kept.
+func revalidateFolder(dir string, cached *indexedFolder, exts map[string]struct{}) (*indexedFolder, error) {
+	if cached != nil {
+		if info, err := os.Stat(dir); err == nil && info.ModTime().UnixNano() == cached.ModTime {
+			folder := &indexedFolder{ModTime: cached.ModTime}
+			for _, f := range cached.Files {
+				info, err := os.Stat(filepath.Join(dir, f.Name))
+				if err != nil {
+					continue
+				}
+				if !f.unchanged(info) {
+					f = indexedFile{Name: f.Name, Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+				}
+				folder.Files = append(folder.Files, f)
+			}
+			return folder, nil
+		}
+	}
+
+	folder, err := scanSampleFolder(dir, exts)
+	if err != nil || cached == nil {
+		return folder, err
+	}
+	for i, f := range folder.Files {
+		if old, ok := cached.file(f.Name); ok && old.Size == f.Size && old.ModTime == f.ModTime {
+			folder.Files[i].Features = old.Features
+		}
+	}
+	return folder, nil
+}
+
+// sampleIndex caches the sample library on disk so it loads without
+// walking the sample roots, which is slow on network mounts
+type sampleIndex struct {
+	path string
+
+	Version int `json:"version"`
+	// Exts are the extensions the index was built with
+	Exts []string `json:"exts"`
+	// Folders are keyed by the bank folder path
+	Folders map[string]*indexedFolder `json:"folders"`
+}
+
+func newSampleIndex(path string, exts map[string]struct{}) *sampleIndex {
+	return &sampleIndex{
+		path:    path,
+		Version: sampleIndexVersion,
+		Exts:    extList(exts),
+		Folders: make(map[string]*indexedFolder),
+	}
+}
+
+func extList(exts map[string]struct{}) []string {
+	list := make([]string, 0, len(exts))
+	for ext := range exts {
+		list = append(list, ext)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// loadSampleIndex reads the index at path. A missing or outdated index,
+// or one built for other extensions, is empty.
+func loadSampleIndex(path string, exts map[string]struct{}) (*sampleIndex, error) {
+	idx := newSampleIndex(path, exts)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, err
+	}
+
+	var cached sampleIndex
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return idx, err
+	}
+	if cached.Version != sampleIndexVersion || strings.Join(cached.Exts, ",") != strings.Join(idx.Exts, ",") {
+		return idx, nil
+	}
+	if cached.Folders != nil {
+		idx.Folders = cached.Folders
+	}
+	return idx, nil
+}
+
+// save writes the index, replacing the previous file atomically
+func (idx *sampleIndex) save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return err
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, idx.path)
+}
+
+// saveCmd writes a snapshot of the index in the background
+func (idx *sampleIndex) saveCmd() tea.Cmd {
+	snapshot := &sampleIndex{
+		path:    idx.path,
+		Version: idx.Version,
+		Exts:    idx.Exts,
+		Folders: make(map[string]*indexedFolder, len(idx.Folders)),
+	}
+	for dir, folder := range idx.Folders {
+		snapshot.Folders[dir] = folder.clone()
+	}
+	return func() tea.Msg {
+		if err := snapshot.save(); err != nil {
+			log.Println("failed to save sample index:", err)
+		}
+		return nil
+	}
+}
+
+// Samples mirrors SuperDirt's loadSoundFiles("root/*") for each root:
+// every top-level folder is a bank named after the folder, holding the sound
+// files directly inside it. A bank in a later root replaces one with the same
+// name from an earlier root.
+func (idx *sampleIndex) Samples(roots []string) map[string][]audioFile {
+	samples := make(map[string][]audioFile)
+	for _, root := range roots {
+		var dirs []string
+		for dir := range idx.Folders {
+			if filepath.Dir(dir) == filepath.Clean(root) {
+				dirs = append(dirs, dir)
+			}
+		}
+		sort.Strings(dirs)
+
+		for _, dir := range dirs {
+			folder := idx.Folders[dir]
+			if len(folder.Files) == 0 {
+				continue
+			}
+			bank := filepath.Base(dir)
+			if _, ok := samples[bank]; ok {
+				log.Printf("replacing sample bank '%s' with %s", bank, dir)
+			}
+			samples[bank] = folder.audioFiles(dir)
+		}
+	}
+	return samples
+}
+
+// Features returns the analyzed features of every indexed file
+func (idx *sampleIndex) Features() map[string]sampleFeatures {
+	features := make(map[string]sampleFeatures)
+	for dir, folder := range idx.Folders {
+		for _, f := range folder.Files {
+			if f.Features != nil {
+				features[filepath.Join(dir, f.Name)] = *f.Features
+			}
+		}
+	}
+	return features
+}
+
+// SetFeatures records the features of an indexed file
+func (idx *sampleIndex) SetFeatures(path string, features sampleFeatures) {
+	folder, ok := idx.Folders[filepath.Dir(path)]
+	if !ok {
+		return
+	}
+	if f, ok := folder.file(filepath.Base(path)); ok {
+		f.Features = &features
+	}
+}
+
+// indexScan revalidates bank folders against the index in batches
+type indexScan struct {
+	roots []string
+	exts  map[string]struct{}
+	// full scans replace the whole index, others only the listed dirs
+	full    bool
+	dirs    []string
+	done    int
+	cached  map[string]*indexedFolder
+	folders map[string]*indexedFolder
+}
+
+// indexScanMsg reports the progress of an index scan
+type indexScanMsg struct {
+	scan *indexScan
+}
+
+// bankDirs lists the bank folders of each root, skipping hidden entries
+// and following symlinked folders like pathMatch does
+func bankDirs(roots []string) []string {
+	var dirs []string
+	for _, root := range roots {
+		log.Println("Loading samples from directory:", root)
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			log.Println("Error loading sample root:", err)
+			continue
+		}
+		for _, entry := range entries {
+			if isHidden(entry.Name()) {
+				continue
+			}
+			dir := filepath.Join(root, entry.Name())
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// newIndexScan prepares a scan of dirs, or of every bank folder when dirs is nil
+func (idx *sampleIndex) newIndexScan(roots []string, exts map[string]struct{}, dirs []string) *indexScan {
+	s := &indexScan{
+		roots:   roots,
+		exts:    exts,
+		full:    dirs == nil,
+		dirs:    dirs,
+		cached:  make(map[string]*indexedFolder, len(idx.Folders)),
+		folders: make(map[string]*indexedFolder),
+	}
+	for dir, folder := range idx.Folders {
+		s.cached[dir] = folder.clone()
+	}
+	return s
+}
+
+// next checks the next batch of folders. A full scan lists the roots first.
+func (s *indexScan) next() tea.Cmd {
+	return func() tea.Msg {
+		if s.full && s.dirs == nil {
+			s.dirs = bankDirs(s.roots)
+			if s.dirs == nil {
+				// nothing to scan, but the roots have been listed
+				s.dirs = []string{}
+			}
+			return indexScanMsg{scan: s}
+		}
+		end := min(s.done+indexScanBatch, len(s.dirs))
+		for _, dir := range s.dirs[s.done:end] {
+			folder, err := revalidateFolder(dir, s.cached[dir], s.exts)
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					log.Println("Error loading sample folder:", err)
+				}
+				folder = nil
+			}
+			s.folders[dir] = folder
+		}
+		s.done = end
+		return indexScanMsg{scan: s}
+	}
+}
+
+func (s *indexScan) Done() bool {
+	return s.dirs != nil && s.done >= len(s.dirs)
+}
+
+// apply stores the scanned folders in the index. Features analyzed while
+// the scan was running are carried over for files that didn't change.
+func (idx *sampleIndex) apply(s *indexScan) {
+	if s.full {
+		current := idx.Folders
+		idx.Folders = make(map[string]*indexedFolder, len(s.folders))
+		for dir, folder := range s.folders {
+			if folder != nil {
+				idx.Folders[dir] = folder
+			}
+		}
+		idx.mergeFeatures(current)
+		return
+	}
+
+	current := make(map[string]*indexedFolder, len(s.folders))
+	for dir, folder := range s.folders {
+		current[dir] = idx.Folders[dir]
+		if folder == nil {
+			delete(idx.Folders, dir)
+			continue
+		}
+		idx.Folders[dir] = folder
+	}
+	idx.mergeFeatures(current)
+}
+
+func (idx *sampleIndex) mergeFeatures(previous map[string]*indexedFolder) {
+	for dir, folder := range idx.Folders {
+		prev, ok := previous[dir]
+		if !ok || prev == nil || prev == folder {
+			continue
+		}
+		for i, f := range folder.Files {
+			if f.Features != nil {
+				continue
+			}
+			if old, ok := prev.file(f.Name); ok && old.Size == f.Size && old.ModTime == f.ModTime {
+				folder.Files[i].Features = old.Features
+			}
+		}
+	}
+}
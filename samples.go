@@ -5,20 +5,43 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// samplesLoadedMsg is sent once samples are loaded
+type samplesLoadedMsg struct {
+	samples map[string][]audioFile
+}
+
+// Banks returns the sample count of each bank
+func (msg samplesLoadedMsg) Banks() map[string]int {
+	banks := make(map[string]int, len(msg.samples))
+	for bank, files := range msg.samples {
+		banks[bank] = len(files)
+	}
+	return banks
+}
+
 // SampleBrowser wraps an audiobrowser and builds all samples from directories of tidal samples
 type SampleBrowser struct {
 	active   bool
-	rootDir  string
+	roots    []string
+	exts     map[string]struct{}
 	onSelect func(path string) tea.Cmd
 	samples  map[string][]audioFile
 	ab       *AudioBrowser
+	// analyzing is set while a feature analysis batch is running
+	analyzing bool
+
+	indexPath string
+	index     *sampleIndex
+	// scan is the running index scan. Folders changed meanwhile are queued.
+	scan       *indexScan
+	queued     []string
+	queuedFull bool
 }
 
 func NewSampleBrowser() *SampleBrowser {
@@ -28,8 +51,9 @@ func NewSampleBrowser() *SampleBrowser {
 		curDir = "."
 	}
 	m := &SampleBrowser{
-		ab:      NewAudioBrowser(),
-		rootDir: curDir,
+		ab:    NewAudioBrowser(),
+		roots: []string{curDir},
+		exts:  sampleExtSet(nil),
 	}
 
 	return m
@@ -52,6 +76,34 @@ func (m *SampleBrowser) SetOnSelect(f func(path string) tea.Cmd) {
 	m.ab.SetOnSelect(f)
 }
 
+func (m *SampleBrowser) SetDB(db *SampleDB) {
+	m.ab.SetDB(db)
+}
+
+func (m *SampleBrowser) CapturingInput() bool {
+	return m.ab.CapturingInput()
+}
+
+func (m *SampleBrowser) KeyHelp() []helpSection {
+	if m.ab.finding {
+		return []helpSection{scopeHelp("sample_finder")}
+	}
+	return []helpSection{scopeHelp("sample_browser")}
+}
+
+// Find opens the sample finder searching for query
+func (m *SampleBrowser) Find(query string) tea.Cmd {
+	return m.ab.Find(query)
+}
+
+func (m *SampleBrowser) SetOnInsert(f func(text string) tea.Cmd) {
+	m.ab.SetOnInsert(f)
+}
+
+func (m *SampleBrowser) SetOnYank(f func(text string) tea.Cmd) {
+	m.ab.SetOnYank(f)
+}
+
 func formatSize(size int64) string {
 	const (
 		KB = 1024
@@ -71,72 +123,201 @@ func formatSize(size int64) string {
 	}
 }
 
-// LoadSamples recursively finds all audio samples in a directory
-// and organizes them by their parent folder name
-func loadSampleMap(rootDir string) (map[string][]audioFile, error) {
-	log.Println("Loading samples from directory:", rootDir)
-	samples := make(map[string][]audioFile)
+// defaultSampleExts are the file extensions SuperDirt's sound library loads by default
+var defaultSampleExts = []string{"wav", "aif", "aiff", "aifc"}
 
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+func sampleExtSet(exts []string) map[string]struct{} {
+	if len(exts) == 0 {
+		exts = defaultSampleExts
+	}
+	set := make(map[string]struct{}, len(exts))
+	for _, ext := range exts {
+		set["."+strings.ToLower(strings.TrimPrefix(ext, "."))] = struct{}{}
+	}
+	return set
+}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
+// isHidden reports whether a file is skipped by sclang's pathMatch globbing
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
 
-		// Only include audio files
-		ext := strings.ToLower(filepath.Ext(path))
-		if _, ok := audioExts[ext]; !ok {
-			return nil
-		}
+// SetSamples replaces the samples shown in the browser and starts
+// analyzing the features of new samples
+func (m *SampleBrowser) SetSamples(samples map[string][]audioFile) tea.Cmd {
+	log.Println("Adding:", len(samples), "banks to audiobrowser")
+	m.samples = samples
+	if m.index != nil {
+		m.ab.ReplaceFeatures(m.index.Features())
+	}
+	return tea.Batch(m.ab.SetFiles(samples), m.analyze())
+}
 
-		// Get parent directory name as the sample bank name
-		parentDir := filepath.Base(filepath.Dir(path))
+// analyze starts analyzing samples without features unless analysis is already running
+func (m *SampleBrowser) analyze() tea.Cmd {
+	if m.analyzing {
+		return nil
+	}
+	missing := m.ab.MissingFeatures()
+	if len(missing) == 0 {
+		return nil
+	}
+	m.analyzing = true
+	m.ab.SetFeatures(nil, len(missing))
+	return analyzeFeatures(missing)
+}
 
-		// Create sample entry
-		sample := audioFile{
-			path:     path,
-			name:     filepath.Base(path),
-			fileType: getFileType(path),
-			size:     formatSize(info.Size()),
+// SetFeatures stores an analyzed batch and continues with the next one
+func (m *SampleBrowser) SetFeatures(msg featuresMsg) tea.Cmd {
+	m.ab.SetFeatures(msg.features, len(msg.pending))
+	if m.index != nil {
+		for path, f := range msg.features {
+			m.index.SetFeatures(path, f)
 		}
+	}
+	if len(msg.pending) > 0 {
+		return analyzeFeatures(msg.pending)
+	}
+	m.analyzing = false
+	var save tea.Cmd
+	if m.index != nil {
+		save = m.index.saveCmd()
+	}
+	// samples may have been added while analyzing
+	return tea.Batch(save, m.analyze())
+}
+
+// SetIndexPath sets where the sample index is cached
+func (m *SampleBrowser) SetIndexPath(path string) {
+	m.indexPath = path
+	m.index = nil
+}
+
+// loadIndex reads the cached index, if any
+func (m *SampleBrowser) loadIndex() {
+	if m.index != nil {
+		return
+	}
+	idx, err := loadSampleIndex(m.indexPath, m.exts)
+	if err != nil {
+		log.Println("failed to load sample index:", err)
+	}
+	m.index = idx
+}
 
-		// Add to map
-		samples[parentDir] = append(samples[parentDir], sample)
+// rescan revalidates dirs against the index, or every bank folder when dirs is nil
+func (m *SampleBrowser) rescan(dirs []string) tea.Cmd {
+	if m.scan != nil {
+		if dirs == nil {
+			m.queuedFull = true
+		}
+		m.queued = append(m.queued, dirs...)
+		return nil
+	}
+	m.loadIndex()
+	m.scan = m.index.newIndexScan(m.roots, m.exts, dirs)
+	m.ab.SetIndexing(0, len(dirs))
+	return m.scan.next()
+}
 
+// UpdateIndex continues an index scan, then shows the revalidated samples
+func (m *SampleBrowser) UpdateIndex(msg indexScanMsg) tea.Cmd {
+	s := msg.scan
+	if s != m.scan {
 		return nil
-	})
+	}
+	if !s.Done() {
+		m.ab.SetIndexing(s.done, len(s.dirs))
+		return s.next()
+	}
+
+	m.index.apply(s)
+	m.scan = nil
+	m.ab.SetIndexing(0, 0)
+	samples := m.index.Samples(m.roots)
+	cmds := []tea.Cmd{
+		m.index.saveCmd(),
+		func() tea.Msg { return samplesLoadedMsg{samples: samples} },
+	}
 
-	return samples, err
+	switch {
+	case m.queuedFull:
+		m.queuedFull, m.queued = false, nil
+		cmds = append(cmds, m.rescan(nil))
+	case len(m.queued) > 0:
+		dirs := m.queued
+		m.queued = nil
+		cmds = append(cmds, m.rescan(dirs))
+	}
+	return tea.Batch(cmds...)
 }
 
-func (m *SampleBrowser) loadSamples() tea.Cmd {
-	return func() tea.Msg {
-		log.Println("------loading samples------")
-		samples, err := loadSampleMap(m.rootDir)
+// BankOf returns the bank a path under one of the sample roots belongs to
+func (m *SampleBrowser) BankOf(path string) (string, bool) {
+	for _, root := range m.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		bank := strings.Split(rel, string(filepath.Separator))[0]
+		if isHidden(bank) {
+			return "", false
+		}
+		return bank, true
+	}
+	return "", false
+}
+
+// WatchDirs returns the sample roots and every bank folder inside them
+func (m *SampleBrowser) WatchDirs() []string {
+	var dirs []string
+	for _, root := range m.roots {
+		entries, err := os.ReadDir(root)
 		if err != nil {
-			log.Println("Error loading samples:", err)
-			return nil
+			continue
 		}
-		m.samples = samples
-		for _, files := range samples {
-			sort.Slice(files, func(i, j int) bool {
-				return strings.ToLower(files[i].name) < strings.ToLower(files[j].name)
-			})
+		dirs = append(dirs, root)
+		for _, entry := range entries {
+			dir := filepath.Join(root, entry.Name())
+			if info, err := os.Stat(dir); err == nil && info.IsDir() && !isHidden(entry.Name()) {
+				dirs = append(dirs, dir)
+			}
 		}
+	}
+	return dirs
+}
 
-		log.Println("Adding:", len(samples), "banks to audiobrowser")
-		return m.ab.SetFiles(samples)
+// reloadBanks rescans only the given banks, keeping the rest of the samples
+func (m *SampleBrowser) reloadBanks(banks []string) tea.Cmd {
+	var dirs []string
+	for _, root := range m.roots {
+		for _, bank := range banks {
+			dirs = append(dirs, filepath.Join(root, bank))
+		}
 	}
+	log.Println("reloading sample banks:", banks)
+	return m.rescan(dirs)
+}
 
+// SetDirectories sets the sample roots, loaded in order. Samples in the
+// cached index are shown right away while the roots are rescanned.
+func (m *SampleBrowser) SetDirectories(paths ...string) tea.Cmd {
+	m.roots = paths
+	m.loadIndex()
+
+	var cached tea.Cmd
+	if samples := m.index.Samples(m.roots); len(samples) > 0 {
+		log.Println("loaded", len(samples), "banks from sample index")
+		cached = func() tea.Msg { return samplesLoadedMsg{samples: samples} }
+	}
+	return tea.Batch(cached, m.rescan(nil))
 }
 
-func (m *SampleBrowser) SetDirectory(path string) tea.Cmd {
-	m.rootDir = path
-	return m.loadSamples()
+// SetExtensions sets the sound file extensions treated as samples
+func (m *SampleBrowser) SetExtensions(exts []string) {
+	m.exts = sampleExtSet(exts)
+	// the index only holds files matching the previous extensions
+	m.index = nil
 }
 
 func (m *SampleBrowser) Init() tea.Cmd {
@@ -148,13 +329,23 @@ func (m *SampleBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// Click forwards clicks inside the border to the browser
+func (m *SampleBrowser) Click(x, y int, double bool) tea.Cmd {
+	return m.ab.Click(x-1, y-1, double)
+}
+
+func (m *SampleBrowser) Scroll(delta int) tea.Cmd {
+	return m.ab.Scroll(delta)
+}
+
 func (m *SampleBrowser) View() string {
 	if !m.active {
 		return ""
 	}
 
 	style := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder())
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(theme.Border)
 
 	return style.Render(m.ab.View())
 }
@@ -5,19 +5,22 @@ import (
 	"io"
 	"log"
 	"os/exec"
+	"sync"
 )
 
 type SCLangRepl struct {
+	// mu guards cmd and stdin, which a restart replaces
+	mu     sync.Mutex
 	cmd    *exec.Cmd
 	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
-	out    chan string
+	out    chan outputLine
 }
 
 func NewSCLangRepl(startupFile string) *SCLangRepl {
 	return &SCLangRepl{
-		out: make(chan string, 100),
+		out: make(chan outputLine, 100),
 	}
 }
 
@@ -26,6 +29,12 @@ func (r *SCLangRepl) buildCmd() *exec.Cmd {
 }
 
 func (r *SCLangRepl) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.start()
+}
+
+func (r *SCLangRepl) start() error {
 	r.cmd = r.buildCmd()
 	var err error
 
@@ -48,13 +57,19 @@ func (r *SCLangRepl) Start() error {
 		return err
 	}
 
-	go r.readOutput(r.stdout)
-	go r.readOutput(r.stderr)
+	go r.readOutput(r.stdout, streamStdout)
+	go r.readOutput(r.stderr, streamStderr)
 
 	return nil
 }
 
 func (r *SCLangRepl) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stop()
+}
+
+func (r *SCLangRepl) stop() error {
 	log.Println("Stopping sclang ...")
 	if r.stdin != nil {
 		r.stdin.Close()
@@ -65,13 +80,27 @@ func (r *SCLangRepl) Stop() error {
 	return nil
 }
 
-func (r *SCLangRepl) readOutput(reader io.Reader) {
+// Restart stops the process, if running, and starts a new one. The old
+// process is reaped after the lock is released.
+func (r *SCLangRepl) Restart() error {
+	r.mu.Lock()
+	old := r.cmd
+	if old != nil {
+		r.stop()
+	}
+	err := r.start()
+	r.mu.Unlock()
+	reapProcess(old)
+	return err
+}
+
+func (r *SCLangRepl) readOutput(reader io.Reader, stream lineStream) {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		r.out <- scanner.Text()
+		r.out <- outputLine{stream: stream, text: scanner.Text()}
 	}
 }
 
-func (r *SCLangRepl) Output() <-chan string {
+func (r *SCLangRepl) Output() <-chan outputLine {
 	return r.out
 }
@@ -0,0 +1,60 @@
+package main
+
+// defaultConsoleScrollback is how many lines a console keeps unless configured
+const defaultConsoleScrollback = 5000
+
+// scrollback is a ring buffer of console lines. Lines are numbered from the
+// first line ever added, so a number keeps pointing at the same line while
+// older lines are dropped.
+type scrollback struct {
+	lines []consoleLine
+	// start is the index of the oldest line in lines
+	start int
+	count int
+	// first is the number of the oldest line
+	first int
+}
+
+func newScrollback(size int) *scrollback {
+	if size <= 0 {
+		size = defaultConsoleScrollback
+	}
+	return &scrollback{lines: make([]consoleLine, size)}
+}
+
+// Push adds a line, dropping the oldest one when full. It reports whether a
+// line was dropped.
+func (s *scrollback) Push(l consoleLine) bool {
+	if s.count < len(s.lines) {
+		s.lines[(s.start+s.count)%len(s.lines)] = l
+		s.count++
+		return false
+	}
+	s.lines[s.start] = l
+	s.start = (s.start + 1) % len(s.lines)
+	s.first++
+	return true
+}
+
+func (s *scrollback) Len() int {
+	return s.count
+}
+
+// First returns the number of the oldest line
+func (s *scrollback) First() int {
+	return s.first
+}
+
+// Get returns the line with number n, which must be in the buffer
+func (s *scrollback) Get(n int) consoleLine {
+	return s.lines[(s.start+n-s.first)%len(s.lines)]
+}
+
+// Texts copies the text of every line, oldest first
+func (s *scrollback) Texts() []string {
+	texts := make([]string, s.count)
+	for i := range texts {
+		texts[i] = s.Get(s.first + i).text
+	}
+	return texts
+}
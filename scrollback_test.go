@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestScrollback(t *testing.T) {
+	s := newScrollback(3)
+	for i, text := range []string{"a", "b"} {
+		if s.Push(consoleLine{text: text}) {
+			t.Errorf("push %d dropped a line", i)
+		}
+	}
+	if got := s.Texts(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("texts = %q", got)
+	}
+
+	var dropped int
+	for _, text := range []string{"c", "d", "e", "f", "g"} {
+		if s.Push(consoleLine{text: text}) {
+			dropped++
+		}
+	}
+	if dropped != 4 {
+		t.Errorf("dropped %d lines, want 4", dropped)
+	}
+	if s.Len() != 3 || s.First() != 4 {
+		t.Errorf("len %d first %d, want 3 and 4", s.Len(), s.First())
+	}
+	if got := s.Texts(); !slices.Equal(got, []string{"e", "f", "g"}) {
+		t.Errorf("texts = %q", got)
+	}
+	for n, want := range map[int]string{4: "e", 5: "f", 6: "g"} {
+		if got := s.Get(n).text; got != want {
+			t.Errorf("line %d = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestScrollbackDefaultSize(t *testing.T) {
+	if got := len(newScrollback(0).lines); got != defaultConsoleScrollback {
+		t.Errorf("size %d, want %d", got, defaultConsoleScrollback)
+	}
+}
+
+// newTestConsole returns an active console holding lines "0" to "n-1" in
+// a scrollback of size lines
+func newTestConsole(size, n int) *Console {
+	c := NewConsole("test", size)
+	c.SetActive(true)
+	c.SetSize(40, 10)
+	for i := range n {
+		c.AddLine(fmt.Sprint(i))
+	}
+	return c
+}
+
+func TestConsoleFilterDropsOldLines(t *testing.T) {
+	c := newTestConsole(5, 5)
+	c.filter = compilePattern("^[02468]$")
+	c.applyFilters()
+	if !slices.Equal(c.shown, []int{0, 2, 4}) {
+		t.Fatalf("shown = %v", c.shown)
+	}
+
+	for i := 5; i < 8; i++ {
+		c.AddLine(fmt.Sprint(i))
+	}
+	// lines 0 to 2 were dropped
+	if !slices.Equal(c.shown, []int{4, 6}) {
+		t.Errorf("shown = %v, want [4 6]", c.shown)
+	}
+	if i, ok := c.shownIndex(6); !ok || i != 1 {
+		t.Errorf("shownIndex(6) = %d, %v", i, ok)
+	}
+	if _, ok := c.shownIndex(5); ok {
+		t.Error("filtered line 5 is shown")
+	}
+	if c.shownLine(0) != 4 {
+		t.Errorf("shownLine(0) = %d, want 4", c.shownLine(0))
+	}
+}
+
+func TestConsolePausedViewKeepsLines(t *testing.T) {
+	c := newTestConsole(20, 20)
+	c.scrollBy(-5)
+	top := c.shownLine(c.top)
+
+	for i := 20; i < 23; i++ {
+		c.AddLine(fmt.Sprint(i))
+	}
+	if got := c.shownLine(c.top); got != top {
+		t.Errorf("top line %d, want %d", got, top)
+	}
+	if c.unseen != 3 {
+		t.Errorf("unseen %d, want 3", c.unseen)
+	}
+}
+
+func TestConsoleFindMatch(t *testing.T) {
+	c := newTestConsole(10, 15)
+	c.search = compilePattern("^1[0-4]?$")
+
+	c.findMatch(true)
+	if c.match != 14 {
+		t.Fatalf("newest match %d, want 14", c.match)
+	}
+	c.findMatch(true)
+	if c.match != 13 {
+		t.Errorf("older match %d, want 13", c.match)
+	}
+	c.findMatch(false)
+	if c.match != 14 {
+		t.Errorf("newer match %d, want 14", c.match)
+	}
+	c.findMatch(false)
+	if c.message != "no newer matches" {
+		t.Errorf("message %q", c.message)
+	}
+
+	// line 1 was dropped, so the oldest match is 10
+	for range 4 {
+		c.findMatch(true)
+	}
+	if c.match != 10 {
+		t.Errorf("oldest match %d, want 10", c.match)
+	}
+	c.findMatch(true)
+	if c.message != "no older matches" {
+		t.Errorf("message %q", c.message)
+	}
+}
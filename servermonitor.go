@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	posc "github.com/treethought/perigee/osc"
+)
+
+const (
+	// defaultServerPort is scsynth's default UDP port
+	defaultServerPort = 57110
+	// defaultServerCPUWarning is the average CPU load, in percent, that
+	// warns unless configured
+	defaultServerCPUWarning = 80
+	serverPollInterval      = time.Second
+)
+
+// serverStatusMsg is the result of polling the audio server
+type serverStatusMsg struct {
+	status posc.ServerStatus
+	err    error
+}
+
+// serverMonitor polls scsynth's /status and shows it in the status line
+type serverMonitor struct {
+	client *posc.StatusClient
+	// cpuWarning is the average CPU load that warns about dropouts
+	cpuWarning float64
+
+	status posc.ServerStatus
+	online bool
+	// overloaded is set while the CPU load is over the warning threshold
+	overloaded bool
+}
+
+func newServerMonitor(port int, cpuWarning float64) *serverMonitor {
+	if port == 0 {
+		port = defaultServerPort
+	}
+	if cpuWarning == 0 {
+		cpuWarning = defaultServerCPUWarning
+	}
+	return &serverMonitor{
+		client:     posc.NewStatusClient("127.0.0.1", port, serverPollInterval/2),
+		cpuWarning: cpuWarning,
+	}
+}
+
+// poll requests the server's status after the poll interval
+func (m *serverMonitor) poll() tea.Cmd {
+	return tea.Tick(serverPollInterval, func(time.Time) tea.Msg {
+		status, err := m.client.Status()
+		return serverStatusMsg{status: status, err: err}
+	})
+}
+
+// Close closes the socket used to poll the server
+func (m *serverMonitor) Close() error {
+	return m.client.Close()
+}
+
+// Update records a poll result. It returns a warning when the server goes
+// down or its CPU load crosses the threshold, and "" otherwise.
+func (m *serverMonitor) Update(msg serverStatusMsg) string {
+	if msg.err != nil {
+		wasOnline := m.online
+		m.online = false
+		m.overloaded = false
+		if wasOnline {
+			log.Println("scsynth stopped answering /status:", msg.err)
+			return "scsynth stopped responding"
+		}
+		return ""
+	}
+
+	m.status = msg.status
+	m.online = true
+	wasOverloaded := m.overloaded
+	m.overloaded = msg.status.AvgCPU >= m.cpuWarning
+	if m.overloaded && !wasOverloaded {
+		return fmt.Sprintf("scsynth CPU at %.0f%% (peak %.0f%%), expect dropouts", msg.status.AvgCPU, msg.status.PeakCPU)
+	}
+	return ""
+}
+
+// Indicator renders the server's status compactly for the status line
+func (m *serverMonitor) Indicator() string {
+	if !m.online {
+		return completionStyle.Render("scsynth ○")
+	}
+	s := m.status
+	dot := lipgloss.NewStyle().Foreground(theme.Success)
+	cpu := completionStyle
+	if m.overloaded {
+		dot = dot.Foreground(theme.Warning)
+		cpu = lipgloss.NewStyle().Foreground(theme.Warning).Bold(true)
+	}
+	return completionStyle.Render("scsynth ") + dot.Render("●") +
+		completionStyle.Render(fmt.Sprintf(" %.1fk ugens %d synths %d ", s.NominalSampleRate/1000, s.UGens, s.Synths)) +
+		cpu.Render(fmt.Sprintf("cpu %.0f%%/%.0f%%", s.AvgCPU, s.PeakCPU))
+}
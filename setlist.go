@@ -0,0 +1,526 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// setMarker lists entries of the set list, each a named block, scene or
+// .tidal file with an optional length in cycles, e.g.
+// -- @set intro:16 verse:32 drop outro.tidal:8
+// Entries without a length play until advanced manually. Several markers
+// add to the same list.
+var setMarker = regexp.MustCompile(`^\s*--\s*@set\b(.*)$`)
+
+// setListMaxTick is the longest wait between checks of the clock while
+// auto-advancing, which also refreshes the progress shown
+const setListMaxTick = 250 * time.Millisecond
+
+type setListKeyMap struct {
+	Up    key.Binding
+	Down  key.Binding
+	Play  key.Binding
+	Next  key.Binding
+	Prev  key.Binding
+	Auto  key.Binding
+	Stop  key.Binding
+	Close key.Binding
+}
+
+var defaultSetListKeyMap = setListKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous entry"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next entry"),
+	),
+	Play: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "play selected entry"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "advance to next entry"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "back to previous entry"),
+	),
+	Auto: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle auto-advance"),
+	),
+	Stop: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "stop the set list"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "close set list"),
+	),
+}
+
+// setEntry is a step of the set list
+type setEntry struct {
+	// name is a named block or scene, or a .tidal file
+	name string
+	// cycles is how long the entry plays before auto-advancing, 0 to wait
+	// for a manual advance
+	cycles int
+}
+
+func (e setEntry) file() bool {
+	return strings.HasSuffix(e.name, ".tidal")
+}
+
+func (e setEntry) String() string {
+	if e.cycles == 0 {
+		return e.name
+	}
+	return fmt.Sprintf("%s:%d", e.name, e.cycles)
+}
+
+// parseSetList reads the set list markers in a buffer
+func parseSetList(lines []string) []setEntry {
+	var entries []setEntry
+	for _, line := range lines {
+		match := setMarker.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, field := range strings.Fields(match[1]) {
+			entry := setEntry{name: strings.TrimPrefix(field, "@")}
+			if i := strings.LastIndex(entry.name, ":"); i > 0 {
+				if n, err := strconv.Atoi(entry.name[i+1:]); err == nil && n > 0 {
+					entry = setEntry{name: entry.name[:i], cycles: n}
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// SetList returns the set list in the buffer
+func (m *Editor) SetList() []setEntry {
+	text := m.e.GetBuffer().Text()
+	if text != m.setListText {
+		m.setListText = text
+		m.setList = parseSetList(m.e.GetBuffer().Lines())
+	}
+	return m.setList
+}
+
+type setListTickMsg struct{}
+
+// SetListPanel steps through the set list, advancing manually or, in auto
+// mode, when an entry's cycles have played
+type SetListPanel struct {
+	clock *cycleClock
+	// play evaluates an entry, through the evaluation queue unless it's
+	// sent on the boundary it starts at
+	play func(name string, queued bool) tea.Cmd
+	// startsAt returns the cycle an entry played at a cycle starts on
+	startsAt func(cycle float64) float64
+
+	entries []setEntry
+	// current is the playing entry, -1 when stopped
+	current int
+	// start is the cycle the current entry started at
+	start   float64
+	auto    bool
+	ticking bool
+
+	cursor  int
+	offset  int
+	active  bool
+	w, h    int
+	message string
+}
+
+func NewSetListPanel(clock *cycleClock) *SetListPanel {
+	return &SetListPanel{clock: clock, current: -1}
+}
+
+// SetOnPlay sets the functions evaluating an entry and finding the cycle
+// it starts on when played by hand
+func (p *SetListPanel) SetOnPlay(play func(name string, queued bool) tea.Cmd, startsAt func(cycle float64) float64) {
+	p.play = play
+	p.startsAt = startsAt
+}
+
+// SetEntries replaces the entries, following the current one when the
+// list is edited. The editor returns the same slice until the buffer
+// changes, so resyncing an unchanged list does nothing.
+func (p *SetListPanel) SetEntries(entries []setEntry) {
+	if len(entries) == len(p.entries) && (len(entries) == 0 || &entries[0] == &p.entries[0]) {
+		return
+	}
+	if p.current >= 0 {
+		name := p.entries[p.current].name
+		// an entry can be repeated, so the current one is only searched
+		// for once its position holds another name
+		if p.current >= len(entries) || entries[p.current].name != name {
+			current := -1
+			for i, e := range entries {
+				if e.name == name {
+					current = i
+					break
+				}
+			}
+			p.current = current
+		}
+		if p.current < 0 {
+			p.auto = false
+		}
+	}
+	p.entries = entries
+	p.cursor = min(p.cursor, max(len(entries)-1, 0))
+}
+
+func (p *SetListPanel) Active() bool {
+	return p.active
+}
+
+func (p *SetListPanel) SetActive(active bool) {
+	p.active = active
+}
+
+func (p *SetListPanel) SetSize(width, height int) {
+	p.w = width
+	p.h = height
+}
+
+func (p *SetListPanel) KeyHelp() []helpSection {
+	return []helpSection{scopeHelp("set_list")}
+}
+
+// Playing reports whether an entry has been started
+func (p *SetListPanel) Playing() bool {
+	return p.current >= 0
+}
+
+// launch plays an entry as if it started at a cycle
+func (p *SetListPanel) launch(i int, start float64, queued bool) tea.Cmd {
+	if i < 0 || i >= len(p.entries) {
+		return nil
+	}
+	p.current = i
+	p.cursor = i
+	p.start = start
+	p.message = ""
+	var cmd tea.Cmd
+	if p.play != nil {
+		cmd = p.play(p.entries[i].name, queued)
+	}
+	return tea.Batch(cmd, p.tick())
+}
+
+// Play starts an entry now, through the evaluation queue. Its cycles
+// count from the boundary it's released at, or from the start of the
+// current cycle when evaluations aren't quantized.
+func (p *SetListPanel) Play(i int) tea.Cmd {
+	cycle := p.clock.Cycle(time.Now())
+	start := math.Floor(cycle)
+	if p.startsAt != nil {
+		start = p.startsAt(cycle)
+	}
+	return p.launch(i, start, true)
+}
+
+// Next advances to the entry after the current one, or starts the list
+func (p *SetListPanel) Next() tea.Cmd {
+	if p.current+1 >= len(p.entries) {
+		p.message = "end of the set list"
+		return nil
+	}
+	return p.Play(p.current + 1)
+}
+
+// Prev goes back to the entry before the current one
+func (p *SetListPanel) Prev() tea.Cmd {
+	if p.current <= 0 {
+		return nil
+	}
+	return p.Play(p.current - 1)
+}
+
+// ToggleAuto switches between advancing manually and at the end of each
+// entry, starting the list if needed
+func (p *SetListPanel) ToggleAuto() tea.Cmd {
+	p.auto = !p.auto
+	if p.auto && p.current < 0 {
+		return p.Play(0)
+	}
+	return p.tick()
+}
+
+// Stop forgets the current entry. What's playing keeps playing.
+func (p *SetListPanel) Stop() {
+	p.current = -1
+	p.auto = false
+}
+
+// end returns the cycle the current entry ends at, if it has a length
+func (p *SetListPanel) end() (float64, bool) {
+	if p.current < 0 || p.entries[p.current].cycles == 0 {
+		return 0, false
+	}
+	return p.start + float64(p.entries[p.current].cycles), true
+}
+
+// tick checks the clock again when the current entry ends, or sooner to
+// refresh its progress
+func (p *SetListPanel) tick() tea.Cmd {
+	end, ok := p.end()
+	if p.ticking || !p.auto || !ok {
+		return nil
+	}
+	p.ticking = true
+	d := p.clock.Until(end, time.Now())
+	if d > setListMaxTick {
+		d = setListMaxTick
+	}
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return setListTickMsg{}
+	})
+}
+
+// advance starts the next entry once the current one has played, exactly
+// at the cycle it ends on
+func (p *SetListPanel) advance() tea.Cmd {
+	end, ok := p.end()
+	if !p.auto || !ok || p.clock.Cycle(time.Now()) < end {
+		return p.tick()
+	}
+	if p.current+1 >= len(p.entries) {
+		p.auto = false
+		p.message = "set list finished"
+		return nil
+	}
+	return p.launch(p.current+1, end, false)
+}
+
+func (p *SetListPanel) Init() tea.Cmd {
+	return nil
+}
+
+func (p *SetListPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(setListTickMsg); ok {
+		p.ticking = false
+		return p, p.advance()
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+	switch {
+	case key.Matches(keyMsg, defaultSetListKeyMap.Up):
+		p.cursor = max(p.cursor-1, 0)
+	case key.Matches(keyMsg, defaultSetListKeyMap.Down):
+		p.cursor = min(p.cursor+1, max(len(p.entries)-1, 0))
+	case key.Matches(keyMsg, defaultSetListKeyMap.Play):
+		return p, p.Play(p.cursor)
+	case key.Matches(keyMsg, defaultSetListKeyMap.Next):
+		return p, p.Next()
+	case key.Matches(keyMsg, defaultSetListKeyMap.Prev):
+		return p, p.Prev()
+	case key.Matches(keyMsg, defaultSetListKeyMap.Auto):
+		return p, p.ToggleAuto()
+	case key.Matches(keyMsg, defaultSetListKeyMap.Stop):
+		p.Stop()
+	case key.Matches(keyMsg, defaultSetListKeyMap.Close):
+		p.active = false
+	}
+	return p, nil
+}
+
+// rows is how many entries fit below the title, current and next lines
+func (p *SetListPanel) rows() int {
+	_, fh := consoleStyle.GetFrameSize()
+	return max(p.h-fh-4, 1)
+}
+
+func (p *SetListPanel) scrollToCursor() {
+	rows := p.rows()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+	p.offset = min(p.offset, max(len(p.entries)-rows, 0))
+}
+
+// Scroll moves the selection by wheel steps
+func (p *SetListPanel) Scroll(delta int) tea.Cmd {
+	p.cursor = min(max(p.cursor+delta, 0), max(len(p.entries)-1, 0))
+	return nil
+}
+
+// Click selects an entry, playing it on a double click
+func (p *SetListPanel) Click(x, y int, double bool) tea.Cmd {
+	_, fh := consoleStyle.GetFrameSize()
+	row := y - fh/2 - 3
+	if row < 0 || p.offset+row >= len(p.entries) {
+		return nil
+	}
+	p.cursor = p.offset + row
+	if double {
+		return p.Play(p.cursor)
+	}
+	return nil
+}
+
+// progress describes how far the current entry has played
+func (p *SetListPanel) progress(width int) string {
+	end, ok := p.end()
+	if !ok {
+		return completionStyle.Render("until advanced")
+	}
+	cycles := p.entries[p.current].cycles
+	played := math.Min(max(p.clock.Cycle(time.Now())-p.start, 0), float64(cycles))
+	label := fmt.Sprintf(" %d/%d", int(played), cycles)
+	barW := max(width-len(label), 4)
+	filled := int(math.Round(float64(barW) * played / (end - p.start)))
+	return lipgloss.NewStyle().Foreground(theme.Accent).Render(strings.Repeat("█", filled)) +
+		completionStyle.Render(strings.Repeat("░", barW-filled)+label)
+}
+
+// Indicator shows the current and next entry for the status line while
+// the set list is playing
+func (p *SetListPanel) Indicator() string {
+	if p.current < 0 {
+		return ""
+	}
+	s := "▶ " + p.entries[p.current].name
+	if end, ok := p.end(); ok {
+		played := int(max(p.clock.Cycle(time.Now())-p.start, 0))
+		s += fmt.Sprintf(" %d/%.0f", min(played, int(end-p.start)), end-p.start)
+	}
+	if p.current+1 < len(p.entries) {
+		s += " → " + p.entries[p.current+1].name
+	}
+	return completionStyle.Render(s)
+}
+
+func (p *SetListPanel) View() string {
+	if !p.active {
+		return ""
+	}
+	p.scrollToCursor()
+	fw, _ := consoleStyle.GetFrameSize()
+	inner := p.w - fw
+
+	mode := "manual"
+	if p.auto {
+		mode = "auto"
+	}
+	title := titleBarStyle.Render("Set list") + completionStyle.Render(fmt.Sprintf(" %s  %s", mode, p.clock))
+	lines := []string{ansi.Truncate(title, inner, "…")}
+
+	label := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	switch {
+	case len(p.entries) == 0:
+		lines = append(lines,
+			completionStyle.Render("no set list, add one with -- @set intro:16 verse:32"),
+			"",
+		)
+	case p.current < 0:
+		lines = append(lines,
+			completionStyle.Render("stopped"),
+			label.Render("next ")+p.entries[0].name,
+		)
+	default:
+		now := label.Render("now  ") + p.entries[p.current].name + " "
+		lines = append(lines, ansi.Truncate(now+p.progress(inner-ansi.StringWidth(now)), inner, "…"))
+		next := completionStyle.Render("end of the set list")
+		if p.current+1 < len(p.entries) {
+			next = p.entries[p.current+1].String()
+		}
+		lines = append(lines, label.Render("next ")+next)
+	}
+
+	for i := p.offset; i < min(p.offset+p.rows(), len(p.entries)); i++ {
+		marker := "  "
+		if i == p.current {
+			marker = "▶ "
+		}
+		style := finderItemStyle
+		if i == p.cursor {
+			style = finderSelectedStyle
+		}
+		lines = append(lines, ansi.Truncate(style.Render(marker+p.entries[i].String()), inner, "…"))
+	}
+	for len(lines) < p.rows()+3 {
+		lines = append(lines, "")
+	}
+	if p.message != "" {
+		lines = append(lines, diagnosticStyle.Render(p.message))
+	} else {
+		lines = append(lines, "")
+	}
+	return consoleStyle.Width(p.w - 2).Render(strings.Join(lines, "\n"))
+}
+
+// splitBlocks splits code into blocks separated by blank lines
+func splitBlocks(text string) []string {
+	var blocks []string
+	var block []string
+	for _, line := range append(strings.Split(text, "\n"), "") {
+		if strings.TrimSpace(line) != "" {
+			block = append(block, line)
+			continue
+		}
+		if len(block) > 0 {
+			blocks = append(blocks, strings.Join(block, "\n"))
+			block = nil
+		}
+	}
+	return blocks
+}
+
+// evalSetEntry evaluates a named block or scene, or every block of a
+// .tidal file relative to the open one. Entries played by hand go through
+// the evaluation queue, while auto-advance sends them right away as it
+// already runs on the boundary the next entry starts at.
+func (a *App) evalSetEntry(name string, queued bool) tea.Cmd {
+	send := a.repl.Send
+	if queued {
+		send = a.queue.Send
+	}
+	entry := setEntry{name: name}
+	if !entry.file() {
+		return a.evalBlockWith(name, send)
+	}
+	path := expandPath(name)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(a.editor.FilePath()), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to read %s: %v", name, err))
+	}
+	cmds := []tea.Cmd{a.editor.e.SetStatusMessage("sent " + name)}
+	for _, block := range splitBlocks(string(data)) {
+		if err := send(block); err != nil {
+			return a.editor.e.SetStatusMessage(fmt.Sprintf("failed to send: %v", err))
+		}
+		cmds = append(cmds, sentMsgCmd(block))
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kujtimiihoxha/vimtea"
+)
+
+// snippetExt is the extension of the files in a snippet library
+const snippetExt = ".tidal"
+
+type snippetKeyMap struct {
+	NextPlaceholder key.Binding
+	PrevPlaceholder key.Binding
+}
+
+var defaultSnippetKeyMap = snippetKeyMap{
+	NextPlaceholder: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next placeholder"),
+	),
+	PrevPlaceholder: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous placeholder"),
+	),
+}
+
+// snippet is a piece of code from a snippet library
+type snippet struct {
+	// name is the file's path in its library without the extension,
+	// e.g. transitions/xfade
+	name string
+	body string
+}
+
+// snippetsDir is the library in the config dir, where saved snippets go
+func snippetsDir() string {
+	return filepath.Join(configDir(), "snippets")
+}
+
+// loadSnippets reads every snippet file in the libraries. A name found in
+// several libraries is taken from the first one.
+func loadSnippets(dirs []string) []snippet {
+	var snippets []snippet
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != snippetExt {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(strings.TrimSuffix(rel, snippetExt))
+			if seen[name] {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("failed to read snippet %s: %v", path, err)
+				return nil
+			}
+			seen[name] = true
+			snippets = append(snippets, snippet{
+				name: name,
+				body: strings.TrimRight(string(data), "\n"),
+			})
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("failed to load snippets from %s: %v", dir, err)
+		}
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].name < snippets[j].name
+	})
+	return snippets
+}
+
+// validSnippetName checks a name given when saving a snippet. Slashes put
+// the snippet in a subdirectory.
+func validSnippetName(name string) error {
+	if name == "" {
+		return errors.New("snippet name is empty")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid snippet name %q", name)
+		}
+	}
+	return nil
+}
+
+// saveSnippet writes code to the config dir's library in the background
+func saveSnippet(name, code string) tea.Cmd {
+	return func() tea.Msg {
+		path := filepath.Join(snippetsDir(), filepath.FromSlash(name)+snippetExt)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Println("failed to save snippet:", err)
+			return vimtea.SetStatusMsg(fmt.Sprintf("failed to save snippet: %v", err))()
+		}
+		if err := os.WriteFile(path, []byte(code+"\n"), 0644); err != nil {
+			log.Println("failed to save snippet:", err)
+			return vimtea.SetStatusMsg(fmt.Sprintf("failed to save snippet: %v", err))()
+		}
+		return vimtea.SetStatusMsg("saved snippet: " + name)()
+	}
+}
+
+// tabStop is a placeholder in an inserted snippet, written $1 or ${1:default}.
+// $0 is where the cursor ends up.
+type tabStop struct {
+	index    int
+	row, col int
+	// length is the length of the default text
+	length int
+}
+
+// expandSnippet removes the placeholder syntax from a snippet body, returning
+// the text to insert and its tab stops in the order they are visited.
+// A placeholder repeated in the body only stops at its first occurrence and
+// \$ is a literal $.
+func expandSnippet(body string) (string, []tabStop) {
+	var sb strings.Builder
+	var stops []tabStop
+	seen := make(map[int]bool)
+	row, col := 0, 0
+
+	write := func(s string) {
+		sb.WriteString(s)
+		if i := strings.LastIndex(s, "\n"); i >= 0 {
+			row += strings.Count(s, "\n")
+			col = len(s) - i - 1
+		} else {
+			col += len(s)
+		}
+	}
+
+	for i := 0; i < len(body); {
+		if body[i] == '\\' && i+1 < len(body) && body[i+1] == '$' {
+			write("$")
+			i += 2
+			continue
+		}
+		if body[i] != '$' {
+			j := i + 1
+			for j < len(body) && body[j] != '$' && body[j] != '\\' {
+				j++
+			}
+			write(body[i:j])
+			i = j
+			continue
+		}
+
+		index, def, n, ok := parsePlaceholder(body[i:])
+		if !ok {
+			write("$")
+			i++
+			continue
+		}
+		if !seen[index] {
+			seen[index] = true
+			stops = append(stops, tabStop{index: index, row: row, col: col, length: len(def)})
+		}
+		write(def)
+		i += n
+	}
+
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := stops[i].index, stops[j].index
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return sb.String(), stops
+}
+
+// parsePlaceholder reads a placeholder at the start of s, returning its
+// index, default text and length in s
+func parsePlaceholder(s string) (int, string, int, bool) {
+	if len(s) < 2 {
+		return 0, "", 0, false
+	}
+	if s[1] != '{' {
+		j := 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		index, err := strconv.Atoi(s[1:j])
+		return index, "", j, err == nil
+	}
+
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return 0, "", 0, false
+	}
+	num, def, _ := strings.Cut(s[2:end], ":")
+	index, err := strconv.Atoi(num)
+	if err != nil || strings.ContainsRune(def, '\n') {
+		return 0, "", 0, false
+	}
+	return index, def, end + 1, true
+}
+
+// snippetSession tracks the tab stops of the last inserted snippet while
+// the editor stays in insert mode
+type snippetSession struct {
+	stops   []tabStop
+	current int
+	// fresh is set until the current placeholder is edited, so typing
+	// replaces its default text
+	fresh bool
+	// lineCount and suffix, the text after the current stop, locate the end
+	// of the stop once it's been edited
+	lineCount int
+	suffix    string
+}
+
+// Stop returns the current tab stop
+func (s *snippetSession) Stop() tabStop {
+	return s.stops[s.current]
+}
+
+// relocate moves the stops after the current one to follow edits made to it
+func (s *snippetSession) relocate(lines []string) {
+	cur := &s.stops[s.current]
+	dRows := len(lines) - s.lineCount
+	endRow := cur.row + dRows
+	oldEnd := cur.col + cur.length
+
+	newEnd := -1
+	if endRow >= 0 && endRow < len(lines) && strings.HasSuffix(lines[endRow], s.suffix) {
+		newEnd = len(lines[endRow]) - len(s.suffix)
+	}
+	for i := range s.stops {
+		st := &s.stops[i]
+		switch {
+		case i == s.current:
+		case st.row == cur.row && st.col >= oldEnd && newEnd >= 0:
+			st.row = endRow
+			st.col = st.col - oldEnd + newEnd
+		case st.row > cur.row:
+			st.row += dRows
+		}
+	}
+	if dRows == 0 && newEnd >= cur.col {
+		cur.length = newEnd - cur.col
+	} else {
+		cur.length = 0
+	}
+}
+
+// insertSnippet inserts a snippet after the cursor and, if it has
+// placeholders, switches to insert mode on the first one. Like
+// insertAtCursor it's a single undo step.
+func (m *Editor) insertSnippet(body string) tea.Cmd {
+	text, stops := expandSnippet(body)
+	if len(stops) == 0 {
+		return m.insertAtCursor(text)
+	}
+	b := m.e.GetBuffer()
+	cursor := m.e.GetCursor()
+	col := m.insertCol()
+	b.InsertAt(cursor.Row, col, text)
+
+	for i := range stops {
+		if stops[i].row == 0 {
+			stops[i].col += col
+		}
+		stops[i].row += cursor.Row
+	}
+	m.snippet = &snippetSession{stops: stops, current: -1}
+	cmd := m.e.SetMode(vimtea.ModeInsert)
+	m.jumpToStop(1)
+	m.checkSamples(false)
+	return cmd
+}
+
+// jumpToStop moves to the next or previous tab stop. Moving past the last
+// one, or reaching $0, ends the session.
+func (m *Editor) jumpToStop(step int) {
+	s := m.snippet
+	lines := m.e.GetBuffer().Lines()
+	if s.current >= 0 {
+		s.relocate(lines)
+	}
+	next := max(s.current+step, 0)
+	if next >= len(s.stops) {
+		m.snippet = nil
+		return
+	}
+	s.current = next
+	stop := s.Stop()
+	if stop.row >= len(lines) || stop.col > len(lines[stop.row]) {
+		m.snippet = nil
+		return
+	}
+	s.lineCount = len(lines)
+	s.suffix = lines[stop.row][min(stop.col+stop.length, len(lines[stop.row])):]
+	s.fresh = stop.length > 0
+	m.e.SetCursor(stop.row, stop.col)
+	if stop.index == 0 {
+		m.snippet = nil
+	}
+}
+
+// updateSnippet handles placeholder keys in insert mode, returning true
+// when the key was consumed. Typing over a fresh placeholder replaces its
+// default text.
+func (m *Editor) updateSnippet(msg tea.KeyMsg) bool {
+	s := m.snippet
+	if s == nil {
+		return false
+	}
+	switch {
+	case key.Matches(msg, defaultSnippetKeyMap.NextPlaceholder):
+		m.jumpToStop(1)
+		return true
+	case key.Matches(msg, defaultSnippetKeyMap.PrevPlaceholder):
+		m.jumpToStop(-1)
+		return true
+	}
+	if !s.fresh {
+		return false
+	}
+	s.fresh = false
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace, tea.KeyBackspace, tea.KeyDelete:
+	default:
+		return false
+	}
+	stop := s.Stop()
+	m.e.GetBuffer().DeleteAt(stop.row, stop.col, stop.row, stop.col+stop.length-1)
+	m.e.SetCursor(stop.row, stop.col)
+	return msg.Type == tea.KeyBackspace || msg.Type == tea.KeyDelete
+}
+
+// snippetView describes the current placeholder for the info line
+func (m *Editor) snippetView(width int) string {
+	s := m.snippet
+	stop := s.Stop()
+	text := fmt.Sprintf("placeholder %d/%d", s.current+1, len(s.stops))
+	if s.fresh {
+		lines := m.e.GetBuffer().Lines()
+		if stop.row < len(lines) && stop.col+stop.length <= len(lines[stop.row]) {
+			line := lines[stop.row]
+			text += fmt.Sprintf(", typing replaces %q", line[stop.col:stop.col+stop.length])
+		}
+	}
+	text += " · " + defaultSnippetKeyMap.NextPlaceholder.Help().Key + " next"
+	return completionStyle.Render(truncate(text, width))
+}
+
+// snippetCommands lists the snippet library for the snippet picker
+func (a *App) snippetCommands() []command {
+	var cmds []command
+	for _, s := range loadSnippets(a.cfg.SnippetRoots()) {
+		cmds = append(cmds, command{
+			name: s.name,
+			run:  func(string) tea.Cmd { return a.editor.insertSnippet(s.body) },
+		})
+	}
+	return cmds
+}
+
+// openSnippets opens the palette listing only snippets, read from disk
+// each time so pulled changes to a shared library show up
+func (a *App) openSnippets() tea.Cmd {
+	cmds := a.snippetCommands()
+	if len(cmds) == 0 {
+		return a.editor.e.SetStatusMessage("no snippets in " + snippetsDir())
+	}
+	a.active = a.qs
+	return a.qs.Pick("Insert snippet", cmds)
+}
+
+// saveBlockSnippet saves the block under the cursor as a snippet
+func (a *App) saveBlockSnippet(name string) tea.Cmd {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if err := validSnippetName(name); err != nil {
+		return a.editor.e.SetStatusMessage(err.Error())
+	}
+	block, _, _ := a.editor.CurrentBlock()
+	if strings.TrimSpace(block) == "" {
+		return a.editor.e.SetStatusMessage("no block under the cursor")
+	}
+	return saveSnippet(name, block)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePlaceholder(t *testing.T) {
+	tests := []struct {
+		s     string
+		index int
+		def   string
+		n     int
+		ok    bool
+	}{
+		{s: "$1 rest", index: 1, n: 2, ok: true},
+		{s: "$12", index: 12, n: 3, ok: true},
+		{s: "$0", index: 0, n: 2, ok: true},
+		{s: "${2:fast 2} rest", index: 2, def: "fast 2", n: 11, ok: true},
+		{s: "${3}", index: 3, n: 4, ok: true},
+		{s: "${1:}", index: 1, n: 5, ok: true},
+		{s: "$", ok: false},
+		{s: "$ s", ok: false},
+		{s: "$x", ok: false},
+		{s: "${1:open", ok: false},
+		{s: "${x:y}", ok: false},
+	}
+	for _, tt := range tests {
+		index, def, n, ok := parsePlaceholder(tt.s)
+		if ok != tt.ok {
+			t.Errorf("parsePlaceholder(%q) ok = %v, want %v", tt.s, ok, tt.ok)
+			continue
+		}
+		if ok && (index != tt.index || def != tt.def || n != tt.n) {
+			t.Errorf("parsePlaceholder(%q) = %d, %q, %d, want %d, %q, %d", tt.s, index, def, n, tt.index, tt.def, tt.n)
+		}
+	}
+}
+
+func TestExpandSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		text  string
+		stops []tabStop
+	}{
+		{
+			name: "no placeholders",
+			body: `d1 $ s "bd"`,
+			text: `d1 $ s "bd"`,
+		},
+		{
+			name: "defaults",
+			body: `d1 $ s "${1:bd}" # gain ${2:1.2}`,
+			text: `d1 $ s "bd" # gain 1.2`,
+			stops: []tabStop{
+				{index: 1, row: 0, col: 8, length: 2},
+				{index: 2, row: 0, col: 19, length: 3},
+			},
+		},
+		{
+			name: "visited by index with $0 last",
+			body: "$0 ${2:b} $1",
+			text: " b ",
+			stops: []tabStop{
+				{index: 1, row: 0, col: 3},
+				{index: 2, row: 0, col: 1, length: 1},
+				{index: 0, row: 0, col: 0},
+			},
+		},
+		{
+			name: "multiple lines",
+			body: "d1\n  $ s \"${1:bd}\"\n  # ${2:speed 2}",
+			text: "d1\n  $ s \"bd\"\n  # speed 2",
+			stops: []tabStop{
+				{index: 1, row: 1, col: 7, length: 2},
+				{index: 2, row: 2, col: 4, length: 7},
+			},
+		},
+		{
+			name: "repeated placeholder stops once",
+			body: "${1:x} + $1",
+			text: "x + ",
+			stops: []tabStop{
+				{index: 1, row: 0, col: 0, length: 1},
+			},
+		},
+		{
+			name: "escaped dollar",
+			body: `\$1 costs $1`,
+			text: "$1 costs ",
+			stops: []tabStop{
+				{index: 1, row: 0, col: 9},
+			},
+		},
+		{
+			name: "dollar without a placeholder",
+			body: `d1 $ s "bd"`,
+			text: `d1 $ s "bd"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, stops := expandSnippet(tt.body)
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if !slices.Equal(stops, tt.stops) {
+				t.Errorf("stops = %+v, want %+v", stops, tt.stops)
+			}
+		})
+	}
+}
+
+func TestSnippetSessionRelocate(t *testing.T) {
+	// d1 $ s "bd" # gain 1.2, with "bd" replaced by "bd*2 sn"
+	s := &snippetSession{
+		stops: []tabStop{
+			{index: 1, row: 0, col: 8, length: 2},
+			{index: 2, row: 0, col: 19, length: 3},
+			{index: 3, row: 1, col: 2, length: 1},
+		},
+		lineCount: 2,
+		suffix:    `" # gain 1.2`,
+	}
+	s.relocate([]string{`d1 $ s "bd*2 sn" # gain 1.2`, "  x"})
+	want := []tabStop{
+		{index: 1, row: 0, col: 8, length: 7},
+		{index: 2, row: 0, col: 24, length: 3},
+		{index: 3, row: 1, col: 2, length: 1},
+	}
+	if !slices.Equal(s.stops, want) {
+		t.Errorf("stops = %+v, want %+v", s.stops, want)
+	}
+
+	// a line break typed in the placeholder moves the stops after it down
+	s.relocate([]string{`d1 $ s "bd*2`, ` sn" # gain 1.2`, "  x"})
+	want = []tabStop{
+		{index: 1, row: 0, col: 8, length: 0},
+		{index: 2, row: 1, col: 12, length: 3},
+		{index: 3, row: 2, col: 2, length: 1},
+	}
+	if !slices.Equal(s.stops, want) {
+		t.Errorf("after a line break stops = %+v, want %+v", s.stops, want)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kujtimiihoxha/vimtea"
+)
+
+// Theme is the color scheme shared by every component. Colors are hex
+// codes like "#FF00FF" or ANSI color numbers like "212". Themes color the
+// text, not the terminal background, so pick one that suits your terminal.
+type Theme struct {
+	// Base is the bundled theme that fills in colors left out of a configured theme
+	Base string `json:"base,omitempty"`
+	// Syntax is the chroma style highlighting code in the editor
+	Syntax string `json:"syntax"`
+
+	Text lipgloss.Color `json:"text"`
+	// Muted is used for hints, counts and line numbers
+	Muted lipgloss.Color `json:"muted"`
+	// Accent is used for dialog borders and section titles
+	Accent lipgloss.Color `json:"accent"`
+	// Info is used for prompts, key names and search matches
+	Info    lipgloss.Color `json:"info"`
+	Warning lipgloss.Color `json:"warning"`
+	Error   lipgloss.Color `json:"error"`
+	// Success is used for the editor status line and the visuals border
+	Success lipgloss.Color `json:"success"`
+	// Surface is the background of title bars, headers and the selected item
+	Surface     lipgloss.Color `json:"surface"`
+	SurfaceText lipgloss.Color `json:"surface_text"`
+	// Selection is the text of the selected item
+	Selection lipgloss.Color `json:"selection"`
+	Border    lipgloss.Color `json:"border"`
+	// Palette colors the visuals
+	Palette []lipgloss.Color `json:"palette"`
+}
+
+var darkTheme = Theme{
+	Syntax:      "autumn",
+	Text:        "#CCCCCC",
+	Muted:       "#999999",
+	Accent:      "#7D56F4",
+	Info:        "#00FFFF",
+	Warning:     "#FFA500",
+	Error:       "#FF5555",
+	Success:     "#00FF00",
+	Surface:     "#333333",
+	SurfaceText: "#FFFFFF",
+	Selection:   "#FFFF00",
+	Border:      "#666666",
+	Palette: []lipgloss.Color{
+		"#FF0000", // Red
+		"#00FF00", // Green
+		"#0000FF", // Blue
+		"#FFFF00", // Yellow
+		"#FF00FF", // Magenta
+		"#00FFFF", // Cyan
+		"#FFA500", // Orange
+		"#800080", // Purple
+		"#008000", // Dark Green
+		"#000080", // Navy
+		"#800000", // Maroon
+		"#FF69B4", // Hot Pink
+	},
+}
+
+// bundledThemes can be selected by name with "theme" in the config
+var bundledThemes = map[string]Theme{
+	"dark": darkTheme,
+	"light": {
+		Syntax:      "github",
+		Text:        "#333333",
+		Muted:       "#767676",
+		Accent:      "#874BFD",
+		Info:        "#006C8F",
+		Warning:     "#B35900",
+		Error:       "#C00000",
+		Success:     "#1A7F37",
+		Surface:     "#E4E4E4",
+		SurfaceText: "#000000",
+		Selection:   "#0033AA",
+		Border:      "#AAAAAA",
+		Palette: []lipgloss.Color{
+			"#D00000", "#1A7F37", "#0033CC", "#B8860B", "#B000B0", "#007C7C",
+			"#D2691E", "#6A1B9A", "#2E5E1E", "#000080", "#800000", "#C71585",
+		},
+	},
+	// high-contrast uses saturated colors and a white selection bar that
+	// survive being washed out by projectors
+	"high-contrast": {
+		Syntax:      "modus-vivendi",
+		Text:        "#FFFFFF",
+		Muted:       "#DDDDDD",
+		Accent:      "#FFFF00",
+		Info:        "#00FFFF",
+		Warning:     "#FFAA00",
+		Error:       "#FF3333",
+		Success:     "#00FF00",
+		Surface:     "#FFFFFF",
+		SurfaceText: "#000000",
+		Selection:   "#000000",
+		Border:      "#FFFFFF",
+		Palette: []lipgloss.Color{
+			"#FF0000", "#00FF00", "#FFFF00", "#00FFFF", "#FF00FF", "#FFFFFF", "#FF8800", "#3399FF",
+		},
+	},
+}
+
+// theme is the active theme. Components read it when they are created.
+var theme = darkTheme
+
+// styles shared by several components, built from the theme by applyTheme
+var (
+	consoleStyle            lipgloss.Style
+	viewStyle               lipgloss.Style
+	dialogBoxStyle          lipgloss.Style
+	titleBarStyle           lipgloss.Style
+	helpTitleStyle          lipgloss.Style
+	helpKeyStyle            lipgloss.Style
+	helpDescStyle           lipgloss.Style
+	completionStyle         lipgloss.Style
+	completionSelectedStyle lipgloss.Style
+	diagnosticStyle         lipgloss.Style
+	finderItemStyle         lipgloss.Style
+	finderSelectedStyle     lipgloss.Style
+	finderMatchStyle        lipgloss.Style
+)
+
+// withDefaults fills in the colors t leaves out from base
+func (t Theme) withDefaults(base Theme) Theme {
+	fill := func(c *lipgloss.Color, def lipgloss.Color) {
+		if *c == "" {
+			*c = def
+		}
+	}
+	if t.Syntax == "" {
+		t.Syntax = base.Syntax
+	}
+	fill(&t.Text, base.Text)
+	fill(&t.Muted, base.Muted)
+	fill(&t.Accent, base.Accent)
+	fill(&t.Info, base.Info)
+	fill(&t.Warning, base.Warning)
+	fill(&t.Error, base.Error)
+	fill(&t.Success, base.Success)
+	fill(&t.Surface, base.Surface)
+	fill(&t.SurfaceText, base.SurfaceText)
+	fill(&t.Selection, base.Selection)
+	fill(&t.Border, base.Border)
+	if len(t.Palette) == 0 {
+		t.Palette = base.Palette
+	}
+	return t
+}
+
+// loadTheme returns the theme selected in the config. Configured themes
+// replace bundled ones of the same name.
+func loadTheme(cfg *Config) (Theme, error) {
+	name := cfg.Theme
+	if name == "" {
+		name = "dark"
+	}
+	t, ok := cfg.Themes[name]
+	if !ok {
+		t, ok = bundledThemes[name]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme %q", name)
+		}
+		return t, nil
+	}
+
+	baseName := t.Base
+	if baseName == "" {
+		baseName = "dark"
+	}
+	base, ok := bundledThemes[baseName]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, baseName)
+	}
+	return t.withDefaults(base), nil
+}
+
+// applyTheme makes t the active theme and rebuilds the shared styles
+func applyTheme(t Theme) {
+	theme = t
+
+	consoleStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.NormalBorder(), true).
+		BorderForeground(t.Border)
+	viewStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(t.Success)
+	dialogBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(1, 0).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(true)
+	titleBarStyle = lipgloss.NewStyle().
+		Background(t.Surface).
+		Foreground(t.SurfaceText).
+		Bold(true).
+		Padding(0, 1)
+
+	helpTitleStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
+	helpKeyStyle = lipgloss.NewStyle().
+		Foreground(t.Info)
+	helpDescStyle = lipgloss.NewStyle().
+		Foreground(t.Text)
+
+	completionStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+	completionSelectedStyle = lipgloss.NewStyle().
+		Foreground(t.Selection).
+		Background(t.Surface).
+		Bold(true)
+	diagnosticStyle = lipgloss.NewStyle().
+		Foreground(t.Warning)
+
+	finderItemStyle = lipgloss.NewStyle().Foreground(t.Text)
+	finderSelectedStyle = lipgloss.NewStyle().
+		Foreground(t.Selection).
+		Background(t.Surface).
+		Bold(true)
+	finderMatchStyle = lipgloss.NewStyle().
+		Foreground(t.Info).
+		Bold(true)
+}
+
+// editorThemeOptions style the vimtea editor with the active theme
+func editorThemeOptions() []vimtea.EditorOption {
+	return []vimtea.EditorOption{
+		vimtea.WithDefaultSyntaxTheme(theme.Syntax),
+		vimtea.WithStatusStyle(
+			lipgloss.NewStyle().Foreground(theme.Success).Bold(true),
+		),
+		vimtea.WithLineNumberStyle(
+			lipgloss.NewStyle().Foreground(theme.Muted).PaddingRight(1),
+		),
+		vimtea.WithCurrentLineNumberStyle(
+			lipgloss.NewStyle().Foreground(theme.SurfaceText).Background(theme.Surface).Bold(true).PaddingRight(1),
+		),
+		vimtea.WithSelectedStyle(lipgloss.NewStyle().Foreground(theme.SurfaceText).Background(theme.Surface)),
+		vimtea.WithCommandStyle(lipgloss.NewStyle().Foreground(theme.Warning).Bold(true)),
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"sort"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -13,10 +15,6 @@ type Visual interface {
 	Reset() tea.Cmd
 }
 
-var viewStyle = lipgloss.NewStyle().
-	Border(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("#00ff00"))
-
 type VisualsView struct {
 	active      bool
 	activeModel Visual
@@ -51,11 +49,27 @@ func (v *VisualsView) Active() bool {
 }
 
 func (v *VisualsView) SetActiveModel(m string) tea.Cmd {
+	if _, ok := v.models[m]; !ok {
+		return nil
+	}
+	if v.activeModel != nil {
+		v.activeModel.SetActive(false)
+	}
 	v.activeModel = v.models[m]
 	v.activeModel.SetActive(true)
 	return v.activeModel.Reset()
 }
 
+// Names returns the names of the visuals in sorted order
+func (v *VisualsView) Names() []string {
+	names := make([]string, 0, len(v.models))
+	for name := range v.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (v *VisualsView) Init() tea.Cmd {
 	cmds := []tea.Cmd{}
 	for _, model := range v.models {
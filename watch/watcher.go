@@ -0,0 +1,108 @@
+package watch
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher watches directories for changes and sends the changed paths
+// in batches once no new events arrive for the debounce interval
+type Watcher struct {
+	w        *fsnotify.Watcher
+	debounce time.Duration
+	out      chan []string
+
+	mu      sync.Mutex
+	watched map[string]struct{}
+}
+
+func NewWatcher(debounce time.Duration) (*Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		w:        w,
+		debounce: debounce,
+		out:      make(chan []string, 10),
+		watched:  make(map[string]struct{}),
+	}, nil
+}
+
+// Add starts watching a directory. Directories already watched are ignored.
+func (w *Watcher) Add(dir string) error {
+	dir = filepath.Clean(dir)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.watched[dir]; ok {
+		return nil
+	}
+	if err := w.w.Add(dir); err != nil {
+		return err
+	}
+	w.watched[dir] = struct{}{}
+	return nil
+}
+
+// Remove stops watching a directory
+func (w *Watcher) Remove(dir string) {
+	dir = filepath.Clean(dir)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.watched[dir]; !ok {
+		return
+	}
+	delete(w.watched, dir)
+	w.w.Remove(dir)
+}
+
+// Start collects events until the watcher is closed
+func (w *Watcher) Start() {
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case ev, ok := <-w.w.Events:
+			if !ok {
+				return
+			}
+			if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+				continue
+			}
+			pending[ev.Name] = struct{}{}
+			timer.Reset(w.debounce)
+
+		case err, ok := <-w.w.Errors:
+			if !ok {
+				return
+			}
+			log.Println("watch error:", err)
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			pending = make(map[string]struct{})
+			select {
+			case w.out <- paths:
+			default:
+				log.Println("dropped watch events")
+			}
+		}
+	}
+}
+
+func (w *Watcher) Close() error {
+	return w.w.Close()
+}
+
+func (w *Watcher) Out() chan []string {
+	return w.out
+}